@@ -2,17 +2,39 @@ package main
 
 import (
 	"bufio"
+	"flag"
+	"fmt"
+	"io"
 	"log"
-	"math"
 	"os"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/collection"
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/intmath"
+)
+
+var (
+	stream   = flag.Bool("stream", false, "process the input one line at a time instead of loading it all into memory")
+	maxValue = flag.Int("max-value", 99999, "largest location ID expected when streaming")
 )
 
 func main() {
+	flag.Parse()
 	log.SetOutput(os.Stdout) // Log to stdout instead of stderr
+	if *stream {
+		start := time.Now()
+		part1, part2, err := Stream(os.Stdin, *maxValue)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Part 1: %d", part1)
+		log.Printf("Part 2: %d", part2)
+		log.Printf("Total time: %s", time.Since(start))
+		return
+	}
 	inputs := GetInputData(os.Stdin)
 
 	p1Start := time.Now()
@@ -46,6 +68,61 @@ func ParseInt(a string) int {
 	return i
 }
 
+// Solves both parts in a single pass over the input without keeping the lists
+// in memory, so it works for inputs that are too big to load all at once.
+//
+// Location IDs are bounded (5 digits in the real input), so instead of sorting
+// we can count how many times each ID shows up on each side. Walking the
+// counts from lowest to highest gives the same pairs as sorting both lists.
+// This uses memory based on the largest ID, not the number of lines.
+func Stream(input io.Reader, maxValue int) (int, int, error) {
+	left := make([]int, maxValue+1)
+	right := make([]int, maxValue+1)
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		l, r := ParseInt(fields[0]), ParseInt(fields[1])
+		if l < 0 || l > maxValue || r < 0 || r > maxValue {
+			return 0, 0, fmt.Errorf("location ID out of range 0-%d: %q", maxValue, scanner.Text())
+		}
+		left[l]++
+		right[r]++
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	// Pair up the smallest remaining IDs on each side, as many at a time as
+	// both sides have.
+	sum := 0
+	l, r := 0, 0
+	lCount, rCount := left[0], right[0]
+	for {
+		for lCount == 0 && l < maxValue {
+			l++
+			lCount = left[l]
+		}
+		for rCount == 0 && r < maxValue {
+			r++
+			rCount = right[r]
+		}
+		if lCount == 0 || rCount == 0 {
+			break
+		}
+
+		pairs := min(lCount, rCount)
+		sum += pairs * intmath.Abs(l-r)
+		lCount -= pairs
+		rCount -= pairs
+	}
+
+	total := 0
+	for id := range left {
+		total += id * left[id] * right[id]
+	}
+	return sum, total, nil
+}
+
 func Part1(inputs []string) int {
 	left := []int{}
 	right := []int{}
@@ -63,26 +140,26 @@ func Part1(inputs []string) int {
 
 	sum := 0
 	for i := range left {
-		sum += int(math.Abs(float64(left[i] - right[i])))
+		sum += intmath.Abs(left[i] - right[i])
 	}
 	return sum
 }
 
 func Part2(inputs []string) int {
 	left := []int{}
-	heatmap := make(map[int]int)
+	heatmap := collection.NewCounter[int]()
 	for _, line := range inputs {
 		fields := strings.Fields(line)
 		left = append(left, ParseInt(fields[0]))
 		right := ParseInt(fields[1])
 
 		// Keep track of number of occurrences for right side numbers
-		heatmap[right] = heatmap[right] + 1
+		heatmap.Add(right)
 	}
 
 	total := 0
 	for i := range left {
-		total += left[i] * heatmap[left[i]]
+		total += left[i] * heatmap.Count(left[i])
 	}
 	return total
 }
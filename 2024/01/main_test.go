@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// Builds a random list of location ID pairs, one pair per line
+func randomLists(rng *rand.Rand, maxValue int) []string {
+	lines := make([]string, rng.Intn(500))
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%d   %d", rng.Intn(maxValue+1), rng.Intn(maxValue+1))
+	}
+	return lines
+}
+
+func TestStreamMatchesInMemory(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for range 500 {
+		// Small ranges make lots of duplicate IDs, which is the tricky part
+		maxValue := []int{0, 1, 10, 1000, 99999}[rng.Intn(5)]
+		lines := randomLists(rng, maxValue)
+
+		part1, part2, err := Stream(strings.NewReader(strings.Join(lines, "\n")), maxValue)
+		if err != nil {
+			t.Fatalf("Stream returned an error: %v", err)
+		}
+		if want := Part1(lines); part1 != want {
+			t.Errorf("Stream Part 1 = %d, want %d for %q", part1, want, lines)
+		}
+		if want := Part2(lines); part2 != want {
+			t.Errorf("Stream Part 2 = %d, want %d for %q", part2, want, lines)
+		}
+	}
+}
+
+func TestStreamOutOfRange(t *testing.T) {
+	for _, input := range []string{
+		"3   4\n100   1\n",
+		"3   4\n1   100\n",
+		"-1   4\n",
+	} {
+		if _, _, err := Stream(strings.NewReader(input), 99); err == nil {
+			t.Errorf("Stream(%q, 99) should fail with an out of range ID", input)
+		}
+	}
+
+	// The largest ID itself is allowed
+	if _, _, err := Stream(strings.NewReader("99   0\n"), 99); err != nil {
+		t.Errorf("Stream with an ID of exactly --max-value failed: %v", err)
+	}
+}
@@ -2,16 +2,44 @@ package main
 
 import (
 	"bufio"
+	"flag"
+	"fmt"
+	"io"
 	"log"
-	"math"
 	"os"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/intmath"
+)
+
+var (
+	minStep  = flag.Int("min", 1, "smallest allowed difference between levels")
+	maxStep  = flag.Int("max", 3, "largest allowed difference between levels")
+	removals = flag.Int("removals", 1, "number of levels the Problem Dampener can remove in Part 2")
+	explain  = flag.Bool("explain", false, "print which levels to remove to make each report safe")
+	stream   = flag.Bool("stream", false, "process the input one line at a time instead of loading it all into memory")
 )
 
 func main() {
+	flag.Parse()
 	log.SetOutput(os.Stdout) // Log to stdout instead of stderr
+	if *minStep < 1 {
+		// A step of 0 is neither increasing nor decreasing, so it's never safe
+		log.Fatalf("--min must be at least 1, got %d", *minStep)
+	}
+	if *stream {
+		start := time.Now()
+		part1, part2, err := Stream(os.Stdin)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Part 1: %d", part1)
+		log.Printf("Part 2: %d", part2)
+		log.Printf("Total time: %s", time.Since(start))
+		return
+	}
 	lines := GetInputData(os.Stdin)
 
 	p1Start := time.Now()
@@ -70,7 +98,7 @@ func CheckNumbers(numbers []int, min int, max int) bool {
 		}
 
 		// Check if diff is within range
-		diff = int(math.Abs(float64(diff)))
+		diff = intmath.Abs(diff)
 		if diff < min || diff > max {
 			return false
 		}
@@ -78,13 +106,106 @@ func CheckNumbers(numbers []int, min int, max int) bool {
 	return true
 }
 
+// Finds the fewest levels that need to be removed from a report to make it
+// safe, as long as it's no more than k of them. Returns the indexes of the
+// levels to remove, and false if the report can't be made safe.
+func MakeSafe(numbers []int, min int, max int, k int) ([]int, bool) {
+	best := []int{}
+	found := false
+	for _, sign := range []int{1, -1} { // Increasing, then decreasing
+		removed, ok := fewestRemovals(numbers, min, max, k, sign)
+		if ok && (!found || len(removed) < len(best)) {
+			best, found = removed, true
+		}
+	}
+	return best, found
+}
+
+// Finds the fewest levels to remove so the report only increases (sign = 1)
+// or decreases (sign = -1) by min-max each step.
+//
+// We work out the fewest removals needed for a safe report that ends by
+// keeping each level. Since we can remove at most k levels, the previous kept
+// level must be one of the k+1 before it, which makes this O(n*k) instead of
+// retrying every possible combination of removals.
+func fewestRemovals(numbers []int, min int, max int, k int, sign int) ([]int, bool) {
+	n := len(numbers)
+	cost := make([]int, n) // Fewest removals for a safe report ending at i
+	prev := make([]int, n) // Previous level kept before i, or -1 if none
+	for i := range numbers {
+		// Worst case we remove everything before this level
+		cost[i], prev[i] = i, -1
+		for j := i - 1; j >= 0 && j >= i-k-1; j-- {
+			diff := (numbers[i] - numbers[j]) * sign
+			if diff >= min && diff <= max && cost[j]+i-j-1 < cost[i] {
+				cost[i], prev[i] = cost[j]+i-j-1, j
+			}
+		}
+	}
+
+	// Pick the best level to end on, removing everything after it
+	end := -1
+	for i := range numbers {
+		if total := cost[i] + n - 1 - i; total <= k && (end == -1 || total < cost[end]+n-1-end) {
+			end = i
+		}
+	}
+	if end == -1 {
+		return nil, n == 0
+	}
+
+	// Walk back through the kept levels, and remove everything else
+	keep := make([]bool, n)
+	for i := end; i != -1; i = prev[i] {
+		keep[i] = true
+	}
+	removed := []int{}
+	for i := range numbers {
+		if !keep[i] {
+			removed = append(removed, i)
+		}
+	}
+	return removed, true
+}
+
+// Prints which levels were removed from report i to make it safe
+func ExplainReport(i int, line string, numbers []int, removed []int) {
+	levels := make([]string, len(removed))
+	for j, index := range removed {
+		levels[j] = fmt.Sprintf("%d (%d)", index, numbers[index])
+	}
+	log.Printf("Report %d: %s is safe after removing %s", i+1, line, strings.Join(levels, ", "))
+}
+
+// Solves both parts in a single pass over the input, only keeping one report
+// in memory at a time. This gives the same answers as Part1 and Part2, but
+// works for inputs that are too big to load all at once.
+func Stream(r io.Reader) (int, int, error) {
+	part1, part2 := 0, 0
+	scanner := bufio.NewScanner(r)
+	for i := 0; scanner.Scan(); i++ {
+		numbers := ParseInts(strings.Fields(scanner.Text()))
+		if CheckNumbers(numbers, *minStep, *maxStep) {
+			part1++
+		}
+		removed, safe := MakeSafe(numbers, *minStep, *maxStep, *removals)
+		if safe {
+			part2++
+		}
+		if *explain && safe && len(removed) > 0 {
+			ExplainReport(i, scanner.Text(), numbers, removed)
+		}
+	}
+	return part1, part2, scanner.Err()
+}
+
 func Part1(inputs []string) int {
 	total := 0
 	for _, line := range inputs {
 		fields := strings.Fields(line)
 		numbers := ParseInts(fields)
 
-		safe := CheckNumbers(numbers, 1, 3)
+		safe := CheckNumbers(numbers, *minStep, *maxStep)
 		if safe {
 			total++
 		}
@@ -94,33 +215,17 @@ func Part1(inputs []string) int {
 
 func Part2(inputs []string) int {
 	total := 0
-	for _, line := range inputs {
+	for i, line := range inputs {
 		fields := strings.Fields(line)
 		numbers := ParseInts(fields)
 
-		safe := CheckNumbers(numbers, 1, 3)
-		if !safe {
-			// If list is not considered safe then try the list again with
-			// a number removed and move to the next once a safe subset is found.
-			// Not the best approach, but simple and easy to use with my Part 1 solution.
-			for i := range numbers {
-				// Create a copy of the list in memory so we don't mangle it
-				// after each iteration (???)
-				clone := make([]int, len(numbers))
-				copy(clone, numbers)
-
-				// Remove the i'th element from the cloned list and check it
-				sub := append(clone[:i], clone[i+1:]...)
-				safe = CheckNumbers(sub, 1, 3)
-				if safe {
-					total++
-					break // Don't test anymore subsets once a safe one is found
-				}
-			}
-		} else {
-			// Original list was already safe
+		removed, safe := MakeSafe(numbers, *minStep, *maxStep, *removals)
+		if safe {
 			total++
 		}
+		if *explain && safe && len(removed) > 0 {
+			ExplainReport(i, line, numbers, removed)
+		}
 	}
 	return total
 }
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Builds random reports that are mostly close to safe, so every part of the
+// rules gets exercised
+func randomReports(rng *rand.Rand) []string {
+	lines := make([]string, rng.Intn(200))
+	for i := range lines {
+		level := rng.Intn(100)
+		step := []int{1, -1}[rng.Intn(2)]
+		levels := make([]string, 1+rng.Intn(10))
+		for j := range levels {
+			levels[j] = strconv.Itoa(level)
+			level += step * rng.Intn(5)
+			if rng.Intn(6) == 0 {
+				level -= step * 3 // Occasionally go the wrong way
+			}
+		}
+		lines[i] = strings.Join(levels, " ")
+	}
+	return lines
+}
+
+func TestStreamMatchesInMemory(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	defer func(k int) { *removals = k }(*removals)
+	for _, k := range []int{0, 1, 2, 3} {
+		*removals = k
+		for range 200 {
+			lines := randomReports(rng)
+			part1, part2, err := Stream(strings.NewReader(strings.Join(lines, "\n")))
+			if err != nil {
+				t.Fatalf("Stream returned an error: %v", err)
+			}
+			if want := Part1(lines); part1 != want {
+				t.Errorf("removals=%d: Stream Part 1 = %d, want %d for %q", k, part1, want, lines)
+			}
+			if want := Part2(lines); part2 != want {
+				t.Errorf("removals=%d: Stream Part 2 = %d, want %d for %q", k, part2, want, lines)
+			}
+		}
+	}
+}
+
+// Tries every combination of up to k removals, fewest first, and returns how
+// many were needed or -1 if none of them work
+func bruteForceRemovals(numbers []int, min int, max int, k int) int {
+	for count := 0; count <= k && count <= len(numbers); count++ {
+		var try func(from int, kept []int, left int) bool
+		try = func(from int, kept []int, left int) bool {
+			if from == len(numbers) {
+				return left == 0 && CheckNumbers(kept, min, max)
+			}
+			if left > 0 && try(from+1, kept, left-1) {
+				return true
+			}
+			return try(from+1, append(kept, numbers[from]), left)
+		}
+		if try(0, []int{}, count) {
+			return count
+		}
+	}
+	return -1
+}
+
+func TestMakeSafeMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(43))
+	for _, k := range []int{0, 1, 2, 3} {
+		for _, steps := range [][2]int{{1, 3}, {2, 4}, {1, 1}} {
+			for _, line := range randomReports(rng) {
+				numbers := ParseInts(strings.Fields(line))
+				want := bruteForceRemovals(numbers, steps[0], steps[1], k)
+				removed, ok := MakeSafe(numbers, steps[0], steps[1], k)
+				if ok != (want != -1) || (ok && len(removed) != want) {
+					t.Errorf("MakeSafe(%v, %d, %d, %d) = %v, %t, want %d removals", numbers, steps[0], steps[1], k, removed, ok, want)
+					continue
+				}
+
+				// The levels it picked really do make the report safe
+				kept := []int{}
+				for i, n := range numbers {
+					if !slices.Contains(removed, i) {
+						kept = append(kept, n)
+					}
+				}
+				if ok && !CheckNumbers(kept, steps[0], steps[1]) {
+					t.Errorf("MakeSafe(%v, %d, %d, %d) removed %v, which leaves %v unsafe", numbers, steps[0], steps[1], k, removed, kept)
+				}
+			}
+		}
+	}
+}
@@ -2,16 +2,34 @@ package main
 
 import (
 	"bufio"
+	"flag"
+	"io"
 	"log"
 	"os"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
 )
 
+var (
+	trace  = flag.Bool("trace", false, "print every instruction run in Part 2 and the state after it")
+	stream = flag.Bool("stream", false, "scan the input as it is read instead of loading it all into memory")
+)
+
 func main() {
+	flag.Parse()
 	log.SetOutput(os.Stdout) // Log to stdout instead of stderr
+	if *stream {
+		start := time.Now()
+		part1, part2, err := Stream(os.Stdin)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Part 1: %d", part1)
+		log.Printf("Part 2: %d", part2)
+		log.Printf("Total time: %s", time.Since(start))
+		return
+	}
 	data := GetInputData(os.Stdin)
 
 	p1Start := time.Now()
@@ -24,6 +42,19 @@ func main() {
 	p2End := time.Since(p2Start)
 	log.Printf("Part 2: %d (%s)", part2, p2End)
 	log.Printf("Total time: %s", p1End+p2End)
+
+	if *trace {
+		m := NewMachine()
+		m.Tracing = true
+		m.Execute(NewInstructionSet(3, Mul, Do, Dont).Tokenize(data))
+		for _, step := range m.Trace {
+			state := "enabled"
+			if !step.Enabled {
+				state = "disabled"
+			}
+			log.Printf("%6d: %-12s %-8s total=%d", step.Offset, step.Text, state, step.Total)
+		}
+	}
 }
 
 // Utility function to read entire input file
@@ -45,34 +76,316 @@ func ParseInt(a string) int {
 	return num
 }
 
-func Part1(data string) int {
-	total := 0
+// Machine is the state of the program as instructions are run.
+type Machine struct {
+	Enabled bool // Whether mul instructions currently do anything
+	Total   int  // Sum of every enabled mul so far
+
+	Tracing bool        // Record every instruction in Trace
+	Trace   []TraceStep // Instructions run so far, if Tracing is set
+}
+
+// TraceStep is a single instruction that was run and the state of the machine
+// after it.
+type TraceStep struct {
+	Offset  int    // Position of the instruction in the input
+	Text    string // The instruction as it appeared, like mul(2,4)
+	Enabled bool
+	Total   int
+}
+
+// NewMachine creates a Machine in its starting state (enabled, nothing summed).
+func NewMachine() *Machine {
+	return &Machine{Enabled: true}
+}
+
+// Instruction is something the interpreter knows how to run, such as
+// mul(a,b). Arity is the number of integer arguments it takes.
+type Instruction struct {
+	Name  string
+	Arity int
+	Run   func(m *Machine, args []int)
+}
+
+// The instructions from the puzzle
+var (
+	Mul = Instruction{Name: "mul", Arity: 2, Run: func(m *Machine, args []int) {
+		if m.Enabled {
+			m.Total += args[0] * args[1]
+		}
+	}}
+	Do   = Instruction{Name: "do", Arity: 0, Run: func(m *Machine, _ []int) { m.Enabled = true }}
+	Dont = Instruction{Name: "don't", Arity: 0, Run: func(m *Machine, _ []int) { m.Enabled = false }}
+)
+
+// InstructionSet is the list of instructions the tokenizer looks for.
+// Arguments are unsigned numbers of 1 to MaxDigits digits.
+type InstructionSet struct {
+	MaxDigits    int
+	instructions []Instruction
+}
 
-	// Simple regex matching - not crazy fast, but certainly the easiest.
-	r := regexp.MustCompile(`mul\(([0-9]{1,3}),([0-9]{1,3})\)`)
-	for _, match := range r.FindAllStringSubmatch(data, -1) {
-		prod := ParseInt(match[1]) * ParseInt(match[2])
-		total += prod
+// NewInstructionSet creates an InstructionSet from the given instructions.
+func NewInstructionSet(maxDigits int, instructions ...Instruction) *InstructionSet {
+	s := &InstructionSet{MaxDigits: maxDigits}
+	for _, inst := range instructions {
+		s.Register(inst)
 	}
-	return total
+	return s
+}
+
+// Register adds a new instruction to the set. When two instructions could
+// match at the same spot the one registered first wins.
+func (s *InstructionSet) Register(inst Instruction) {
+	s.instructions = append(s.instructions, inst)
+}
+
+// Token is a single well-formed instruction found in the input.
+type Token struct {
+	Instruction *Instruction
+	Args        []int
+	Offset      int    // Position of the instruction in the input
+	Text        string // The instruction as it appeared, like mul(2,4)
+}
+
+// Tokenize finds every well-formed instruction in the (corrupted) input, in
+// order. Anything that isn't exactly name(arg,...) with the right number of
+// arguments is skipped.
+func (s *InstructionSet) Tokenize(data string) []Token {
+	tokens := []Token{}
+	for i := 0; i < len(data); i++ {
+		for n := range s.instructions {
+			inst := &s.instructions[n]
+			args, end, ok := s.parse(data, i, inst)
+			if ok {
+				tokens = append(tokens, Token{Instruction: inst, Args: args, Offset: i, Text: data[i:end]})
+				i = end - 1 // Skip past it, the loop will add the last 1
+				break
+			}
+		}
+	}
+	return tokens
+}
+
+// Tries to parse inst at position i of data, returning its arguments and where
+// it ends
+func (s *InstructionSet) parse(data string, i int, inst *Instruction) ([]int, int, bool) {
+	if !strings.HasPrefix(data[i:], inst.Name) {
+		return nil, 0, false
+	}
+	i += len(inst.Name)
+	if i >= len(data) || data[i] != '(' {
+		return nil, 0, false
+	}
+	i++
+
+	args := make([]int, 0, inst.Arity)
+	for n := range inst.Arity {
+		if n > 0 {
+			if i >= len(data) || data[i] != ',' {
+				return nil, 0, false
+			}
+			i++
+		}
+
+		start := i
+		for i < len(data) && i-start < s.MaxDigits && data[i] >= '0' && data[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return nil, 0, false
+		}
+		args = append(args, ParseInt(data[start:i]))
+	}
+
+	if i >= len(data) || data[i] != ')' {
+		return nil, 0, false
+	}
+	return args, i + 1, true
+}
+
+// Execute runs each of the tokens in order.
+func (m *Machine) Execute(tokens []Token) {
+	for _, t := range tokens {
+		t.Instruction.Run(m, t.Args)
+		if m.Tracing {
+			m.Trace = append(m.Trace, TraceStep{Offset: t.Offset, Text: t.Text, Enabled: m.Enabled, Total: m.Total})
+		}
+	}
+}
+
+// Scanner is a hand-written state machine that finds mul(a,b), do() and
+// don't() in a single pass, one byte at a time, without allocating. It only
+// knows the puzzle's instructions, so use an InstructionSet for anything else.
+//
+// Input can be written in chunks of any size, and instructions may be split
+// across chunks. Line breaks are skipped, the same as joining the lines.
+type Scanner struct {
+	Sum        int // Every mul, for Part 1
+	EnabledSum int // Only the muls after do(), for Part 2
+
+	disabled bool
+	state    scanState
+	a        int
+	b        int
+	digits   int
+}
+
+type scanState int
+
+// How much of an instruction the Scanner has matched so far
+const (
+	scanIdle     scanState = iota
+	scanM                  // m
+	scanMu                 // mu
+	scanMul                // mul
+	scanFirst              // mul(1
+	scanSecond             // mul(1,2
+	scanD                  // d
+	scanDo                 // do
+	scanDoOpen             // do(
+	scanDon                // don
+	scanDonQuote           // don'
+	scanDont               // don't
+	scanDontOpen           // don't(
+)
+
+// Write scans the next chunk of input. It never fails.
+func (s *Scanner) Write(p []byte) (int, error) {
+	scan(s, p)
+	return len(p), nil
+}
+
+// WriteString scans the next chunk of input. It never fails.
+func (s *Scanner) WriteString(str string) (int, error) {
+	scan(s, str)
+	return len(str), nil
+}
+
+// Runs every byte in data through the scanner
+func scan[T string | []byte](s *Scanner, data T) {
+	for i := 0; i < len(data); i++ {
+		s.step(data[i])
+	}
+}
+
+// Advances the state machine by a single byte
+func (s *Scanner) step(c byte) {
+	if c == '\n' || c == '\r' {
+		return
+	}
+
+	switch s.state {
+	case scanM:
+		if c == 'u' {
+			s.state = scanMu
+			return
+		}
+	case scanMu:
+		if c == 'l' {
+			s.state = scanMul
+			return
+		}
+	case scanMul:
+		if c == '(' {
+			s.state, s.a, s.digits = scanFirst, 0, 0
+			return
+		}
+	case scanFirst:
+		if c >= '0' && c <= '9' && s.digits < 3 {
+			s.a = s.a*10 + int(c-'0')
+			s.digits++
+			return
+		} else if c == ',' && s.digits > 0 {
+			s.state, s.b, s.digits = scanSecond, 0, 0
+			return
+		}
+	case scanSecond:
+		if c >= '0' && c <= '9' && s.digits < 3 {
+			s.b = s.b*10 + int(c-'0')
+			s.digits++
+			return
+		} else if c == ')' && s.digits > 0 {
+			s.Sum += s.a * s.b
+			if !s.disabled {
+				s.EnabledSum += s.a * s.b
+			}
+			s.state = scanIdle
+			return
+		}
+	case scanD:
+		if c == 'o' {
+			s.state = scanDo
+			return
+		}
+	case scanDo:
+		if c == '(' {
+			s.state = scanDoOpen
+			return
+		} else if c == 'n' {
+			s.state = scanDon
+			return
+		}
+	case scanDoOpen:
+		if c == ')' {
+			s.disabled = false
+			s.state = scanIdle
+			return
+		}
+	case scanDon:
+		if c == '\'' {
+			s.state = scanDonQuote
+			return
+		}
+	case scanDonQuote:
+		if c == 't' {
+			s.state = scanDont
+			return
+		}
+	case scanDont:
+		if c == '(' {
+			s.state = scanDontOpen
+			return
+		}
+	case scanDontOpen:
+		if c == ')' {
+			s.disabled = true
+			s.state = scanIdle
+			return
+		}
+	}
+
+	// Nothing is in progress, or what we had didn't pan out. None of the
+	// instructions have an 'm' or 'd' after their first letter, so the only
+	// place a new one could start is this byte.
+	switch c {
+	case 'm':
+		s.state = scanM
+	case 'd':
+		s.state = scanD
+	default:
+		s.state = scanIdle
+	}
+}
+
+// Solves both parts while reading the input, so it never needs to be held in
+// memory all at once.
+func Stream(input io.Reader) (int, int, error) {
+	var s Scanner
+	if _, err := io.Copy(&s, input); err != nil {
+		return 0, 0, err
+	}
+	return s.Sum, s.EnabledSum, nil
+}
+
+func Part1(data string) int {
+	var s Scanner
+	s.WriteString(data)
+	return s.Sum
 }
 
 func Part2(data string) int {
-	total := 0
-	doing := true
-
-	// We can still use regex since the matches will be in order,
-	// so we can just flip back and forth.
-	r := regexp.MustCompile(`(don't\(\)|do\(\)|mul\(([0-9]{1,3}),([0-9]{1,3})\))`)
-	for _, match := range r.FindAllStringSubmatch(data, -1) {
-		if match[1] == `do()` { // enabled
-			doing = true
-		} else if match[1] == `don't()` { // disabled
-			doing = false
-		} else if doing { // numbers
-			prod := ParseInt(match[2]) * ParseInt(match[3])
-			total += prod
-		}
-	}
-	return total
+	var s Scanner
+	s.WriteString(data)
+	return s.EnabledSum
 }
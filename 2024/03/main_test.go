@@ -0,0 +1,199 @@
+package main
+
+import (
+	"math/rand"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// The original regex solutions, kept as a reference for the scanner and the
+// interpreter
+var (
+	mulRegex    = regexp.MustCompile(`mul\(([0-9]{1,3}),([0-9]{1,3})\)`)
+	doDontRegex = regexp.MustCompile(`(don't\(\)|do\(\)|mul\(([0-9]{1,3}),([0-9]{1,3})\))`)
+)
+
+func regexPart1(data string) int {
+	total := 0
+	for _, match := range mulRegex.FindAllStringSubmatch(data, -1) {
+		total += ParseInt(match[1]) * ParseInt(match[2])
+	}
+	return total
+}
+
+func regexPart2(data string) int {
+	total := 0
+	doing := true
+	for _, match := range doDontRegex.FindAllStringSubmatch(data, -1) {
+		if match[1] == `do()` {
+			doing = true
+		} else if match[1] == `don't()` {
+			doing = false
+		} else if doing {
+			total += ParseInt(match[2]) * ParseInt(match[3])
+		}
+	}
+	return total
+}
+
+// Pieces that are likely to trip up a scanner when glued together at random
+var fragments = []string{
+	"mul(", "do()", "don't()", "do(", "don't(", ")", ",", "(", "mul", "d", "o",
+	"n't", "m", "1", "23", "456", "7890", "x", " ", "\n", "mul(1,2)",
+	"mul(123,4)", "mul(1234,5)", "mul(12,345)", "mul( 1,2)", "mmul(3,3)",
+	"don't()do()", "mul(1,\n2)", "do\n()",
+}
+
+// Builds a random corrupted program, which may have line breaks
+func randomInput(rng *rand.Rand) string {
+	var sb strings.Builder
+	for range rng.Intn(200) {
+		sb.WriteString(fragments[rng.Intn(len(fragments))])
+	}
+	return sb.String()
+}
+
+// Inputs are joined into a single line before solving, same as GetInputData
+func joinLines(input string) string {
+	return strings.ReplaceAll(input, "\n", "")
+}
+
+var edgeCases = []string{
+	"",
+	"xmul(2,4)%&mul[3,7]!@^do_not_mul(5,5)+mul(32,64]then(mul(11,8)mul(8,5))",
+	"xmul(2,4)&mul[3,7]!^don't()_mul(5,5)+mul(32,64](mul(11,8)undo()?mul(8,5))",
+	"mul(999,999)",
+	"mul(1000,1)mul(1,1000)",
+	"mul(,1)mul(1,)mul()",
+	"mul(1,2",
+	"mmul(2,3)ddo()don't()dodon't()mul(4,5)",
+	"don't()mul(2,3)do()mul(4,5)",
+	"mul(1,\n2)do\n()",
+	"don'tmul(2,2)don't(mul(3,3)",
+}
+
+func TestScannerMatchesRegex(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	inputs := slices.Clone(edgeCases)
+	for range 2000 {
+		inputs = append(inputs, randomInput(rng))
+	}
+
+	for _, input := range inputs {
+		data := joinLines(input)
+		want1, want2 := regexPart1(data), regexPart2(data)
+
+		if got := Part1(data); got != want1 {
+			t.Errorf("Part1(%q) = %d, want %d", data, got, want1)
+		}
+		if got := Part2(data); got != want2 {
+			t.Errorf("Part2(%q) = %d, want %d", data, got, want2)
+		}
+
+		// Write the raw input (with line breaks) in random sized chunks, so
+		// instructions get split across writes
+		var s Scanner
+		for rest := input; len(rest) > 0; {
+			n := 1 + rng.Intn(min(len(rest), 16))
+			s.Write([]byte(rest[:n]))
+			rest = rest[n:]
+		}
+		if s.Sum != want1 || s.EnabledSum != want2 {
+			t.Errorf("chunked Scanner(%q) = %d, %d, want %d, %d", input, s.Sum, s.EnabledSum, want1, want2)
+		}
+
+		part1, part2, err := Stream(strings.NewReader(input))
+		if err != nil || part1 != want1 || part2 != want2 {
+			t.Errorf("Stream(%q) = %d, %d, %v, want %d, %d", input, part1, part2, err, want1, want2)
+		}
+	}
+}
+
+func TestInterpreterMatchesRegex(t *testing.T) {
+	rng := rand.New(rand.NewSource(47))
+	inputs := slices.Clone(edgeCases)
+	for range 2000 {
+		inputs = append(inputs, randomInput(rng))
+	}
+
+	for _, input := range inputs {
+		data := joinLines(input)
+
+		m := NewMachine()
+		m.Execute(NewInstructionSet(3, Mul).Tokenize(data))
+		if want := regexPart1(data); m.Total != want {
+			t.Errorf("mul only interpreter(%q) = %d, want %d", data, m.Total, want)
+		}
+
+		m = NewMachine()
+		m.Execute(NewInstructionSet(3, Mul, Do, Dont).Tokenize(data))
+		if want := regexPart2(data); m.Total != want {
+			t.Errorf("interpreter(%q) = %d, want %d", data, m.Total, want)
+		}
+	}
+}
+
+func TestInterpreterTrace(t *testing.T) {
+	m := NewMachine()
+	m.Tracing = true
+	m.Execute(NewInstructionSet(3, Mul, Do, Dont).Tokenize("mul(2,4)don't()mul(5,5)do()mul(8,5)"))
+
+	want := []TraceStep{
+		{Offset: 0, Text: "mul(2,4)", Enabled: true, Total: 8},
+		{Offset: 8, Text: "don't()", Enabled: false, Total: 8},
+		{Offset: 15, Text: "mul(5,5)", Enabled: false, Total: 8},
+		{Offset: 23, Text: "do()", Enabled: true, Total: 8},
+		{Offset: 27, Text: "mul(8,5)", Enabled: true, Total: 48},
+	}
+	if len(m.Trace) != len(want) {
+		t.Fatalf("got %d trace steps, want %d: %+v", len(m.Trace), len(want), m.Trace)
+	}
+	for i := range want {
+		if m.Trace[i] != want[i] {
+			t.Errorf("step %d = %+v, want %+v", i, m.Trace[i], want[i])
+		}
+	}
+}
+
+// A big input made of random programs, for the benchmarks
+func benchInput() string {
+	rng := rand.New(rand.NewSource(1))
+	var sb strings.Builder
+	for sb.Len() < 1<<20 {
+		sb.WriteString(joinLines(randomInput(rng)))
+	}
+	return sb.String()
+}
+
+func BenchmarkRegex(b *testing.B) {
+	data := benchInput()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for range b.N {
+		regexPart1(data)
+		regexPart2(data)
+	}
+}
+
+func BenchmarkInterpreter(b *testing.B) {
+	data := benchInput()
+	set := NewInstructionSet(3, Mul, Do, Dont)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for range b.N {
+		NewMachine().Execute(set.Tokenize(data))
+	}
+}
+
+func BenchmarkScanner(b *testing.B) {
+	data := benchInput()
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		var s Scanner
+		s.WriteString(data)
+	}
+}
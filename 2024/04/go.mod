@@ -1,3 +1,7 @@
 module github.com/IAreKyleW00t/advent-of-code/2024/04
 
 go 1.23.3
+
+require github.com/IAreKyleW00t/advent-of-code/2024/lib v0.0.0
+
+replace github.com/IAreKyleW00t/advent-of-code/2024/lib => ../lib
@@ -2,12 +2,29 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"image/color"
+	"iter"
 	"log"
 	"os"
+	"strings"
 	"time"
+
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/grid"
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/render"
+)
+
+var (
+	snapshot   = flag.String("snapshot", "", "export an image of the XMAS words found (.png or .svg)")
+	find       = flag.String("find", "", "comma separated list of words to search for, printing where each one is")
+	directions = flag.String("directions", "all", "directions to search for --find words in (all, orthogonal or diagonal)")
+	pattern    = flag.String("pattern", "", "shape to search for with rows separated by / and . as a wildcard, printing where each one is")
 )
 
 func main() {
+	flag.Parse()
 	log.SetOutput(os.Stdout) // Log to stdout instead of stderr
 	data := GetInputData(os.Stdin)
 
@@ -21,6 +38,25 @@ func main() {
 	p2End := time.Since(p2Start)
 	log.Printf("Part 2: %d (%s)", part2, p2End)
 	log.Printf("Total time: %s", p1End+p2End)
+
+	if *snapshot != "" {
+		if err := Snapshot(data, *snapshot); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Saved snapshot to %s", *snapshot)
+	}
+
+	if *find != "" {
+		if err := Find(data, strings.Split(*find, ","), *directions); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *pattern != "" {
+		if err := FindPattern(data, *pattern); err != nil {
+			log.Fatal(err)
+		}
+	}
 }
 
 // Utility function to read entire input file
@@ -33,124 +69,307 @@ func GetInputData(file *os.File) []string {
 	return lines
 }
 
-func SearchWord(x int, y int, graph []string) int {
-	maxY := len(graph)
-	maxX := len(graph[0])
-	matches := 0
+// Match is a single word found in the word search.
+type Match struct {
+	Start grid.Point // Position of the first letter
+	Dir   grid.Point // Step between each letter, such as {1, 0} for left to right
+	Len   int        // Number of letters in the word
+}
 
-	// Check every cardinal and intercardinal direction for possible XMAS matches.
-	// We can be clever about this by multiplying by 1/-1 to change the direction
-	// that is being checked. This is essentially the same speed as "unrolling"
-	// the loops yourself, but this logic is a lot cleaner (imo).
-	//
-	// To be efficient with memory/cache thrashing and reduce array operations we
-	// cram the 4 bytes that we are checking against into an integer by
-	// bitshifting it into it. This is a small bit faster than dealing with array,
-	// especially zero'ing it out after each check.
-	//
-	// 1396788568 is the magic integer number for XMAS, which actually spells SAMX
-	// because we push data in from the right side of the integer.
-	// 01010011 01000001 01001101 01011000
-	//     S       A        M        X
-	for _, i := range []int{1, -1} {
-		// left/right
-		buffer := 0
-		for j := range 4 {
-			// Don't go out of bounds
-			if x+(j*i) < 0 || x+(j*i) >= maxX {
-				break
+// Points returns the position of every letter in the match.
+func (m Match) Points() []grid.Point {
+	points := make([]grid.Point, m.Len)
+	for i := range points {
+		points[i] = m.Start.Add(m.Dir.Scale(i))
+	}
+	return points
+}
+
+// SearchWord finds every occurrence of word in the grid, reading in any of the
+// given directions (such as grid.Surrounding for all 8). Use a byte grid for
+// plain ASCII puzzles, or a rune grid (grid.FromRunes) when the puzzle has
+// multi-byte characters.
+func SearchWord[T byte | rune](g *grid.Grid[T], word []T, dirs []grid.Point) iter.Seq[Match] {
+	return func(yield func(Match) bool) {
+		if len(word) == 0 {
+			return
+		}
+
+		match := wordMatcher(word)
+		for p, c := range g.All() {
+			if c != word[0] {
+				continue
+			}
+			for _, d := range dirs {
+				// The word is a straight line, so if the last letter is in
+				// bounds then all of them are
+				if !g.In(p.Add(d.Scale(len(word)-1))) || !match(g, p, d) {
+					continue
+				}
+				if !yield(Match{Start: p, Dir: d, Len: len(word)}) {
+					return
+				}
 			}
-			buffer |= int(graph[y][x+(j*i)]) << (8 * j)
 		}
-		if buffer == 1396788568 {
-			matches++
+	}
+}
+
+// Picks how to compare word against the grid starting at p and moving in
+// direction d, which must stay in bounds for the whole word.
+func wordMatcher[T byte | rune](word []T) func(*grid.Grid[T], grid.Point, grid.Point) bool {
+	packable := len(word) <= 8
+	for _, c := range word {
+		if c > 0xff {
+			packable = false
 		}
+	}
 
-		// up/down
-		buffer = 0
-		for j := range 4 {
-			// Don't go out of bounds
-			if y+(j*i) < 0 || y+(j*i) >= maxY {
-				break
+	if !packable {
+		return func(g *grid.Grid[T], p grid.Point, d grid.Point) bool {
+			for _, c := range word {
+				if g.Get(p) != c {
+					return false
+				}
+				p = p.Add(d)
 			}
-			buffer |= int(graph[y+(j*i)][x]) << (8 * j)
-		}
-		if buffer == 1396788568 {
-			matches++
+			return true
 		}
+	}
 
-		// up-left/down-right
-		buffer = 0
-		for j := range 4 {
-			// Don't go out of bounds
-			if x+(j*i) < 0 || x+(j*i) >= maxX || y+(j*i) < 0 || y+(j*i) >= maxY {
-				break
+	// To be efficient with memory/cache thrashing and reduce array operations
+	// we cram the (up to 8) bytes that we are checking against into an
+	// integer by bitshifting them into it. This is a small bit faster than
+	// comparing each letter, especially on the real input where most
+	// directions fail after a letter or two anyway.
+	//
+	// For example XMAS becomes 1396788568, which actually spells SAMX because
+	// we push data in from the right side of the integer.
+	// 01010011 01000001 01001101 01011000
+	//     S       A        M        X
+	target := uint64(0)
+	for i, c := range word {
+		target |= uint64(c) << (8 * i)
+	}
+	return func(g *grid.Grid[T], p grid.Point, d grid.Point) bool {
+		buffer := uint64(0)
+		for i := range word {
+			c := g.Get(p)
+			if c > 0xff { // Can't be part of the word, and would overflow the byte
+				return false
 			}
-			buffer |= int(graph[y+(j*i)][x+(j*i)]) << (8 * j)
+			buffer |= uint64(c) << (8 * i)
+			p = p.Add(d)
 		}
-		if buffer == 1396788568 {
-			matches++
+		return buffer == target
+	}
+}
+
+// Pattern is a small 2D shape to look for in a grid, such as the X-MAS in
+// Part 2. Cells that are the wildcard match anything. Every rotation and
+// reflection of the shape is searched for.
+type Pattern struct {
+	Wildcard     byte
+	orientations []orientation
+}
+
+// A single rotation/reflection of a pattern
+type orientation struct {
+	shape *grid.Grid[byte]
+	cells []grid.Point // Offsets of every cell that isn't a wildcard
+}
+
+// PatternMatch is a single place a pattern was found.
+type PatternMatch struct {
+	Origin grid.Point       // Top-left corner of the match
+	Shape  *grid.Grid[byte] // Which orientation of the pattern matched
+	cells  []grid.Point
+}
+
+// Points returns the position of every cell in the match that isn't a
+// wildcard.
+func (m PatternMatch) Points() []grid.Point {
+	points := make([]grid.Point, len(m.cells))
+	for i, c := range m.cells {
+		points[i] = m.Origin.Add(c)
+	}
+	return points
+}
+
+// NewPattern creates a Pattern from the lines of a shape, where the wildcard
+// character matches anything. Orientations that look the same (because the
+// shape is symmetric) are only searched once, so they aren't double counted.
+// Every line must be the same length, so pad short ones with the wildcard.
+func NewPattern(lines []string, wildcard byte) (*Pattern, error) {
+	if len(lines) == 0 || len(lines[0]) == 0 {
+		return nil, errors.New("pattern is empty")
+	}
+	for i, line := range lines {
+		if len(line) != len(lines[0]) {
+			return nil, fmt.Errorf("pattern row %d is %d wide, but row 1 is %d wide", i+1, len(line), len(lines[0]))
 		}
+	}
 
-		// down-left/up-right
-		buffer = 0
-		for j := range 4 {
-			// Don't go out of bounds
-			if x+(j*i) < 0 || x+(j*i) >= maxX || y-(j*i) < 0 || y-(j*i) >= maxY {
-				break
+	p := &Pattern{Wildcard: wildcard}
+	seen := map[string]bool{}
+	for _, shape := range grid.FromLines(lines).Orientations() {
+		key := shape.Render(func(_ grid.Point, c byte) rune { return rune(c) })
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		o := orientation{shape: shape}
+		for pos, c := range shape.All() {
+			if c != wildcard {
+				o.cells = append(o.cells, pos)
 			}
-			buffer |= int(graph[y-(j*i)][x+(j*i)]) << (8 * j)
 		}
-		if buffer == 1396788568 {
-			matches++
+		p.orientations = append(p.orientations, o)
+	}
+	return p, nil
+}
+
+// MustPattern is like NewPattern, but panics if the pattern is invalid. This
+// is meant for patterns that are hard-coded.
+func MustPattern(lines []string, wildcard byte) *Pattern {
+	p, err := NewPattern(lines, wildcard)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Find locates every occurrence of the pattern (in any orientation) in g.
+func (p *Pattern) Find(g *grid.Grid[byte]) iter.Seq[PatternMatch] {
+	return func(yield func(PatternMatch) bool) {
+		for _, o := range p.orientations {
+			for y := 0; y+o.shape.Height() <= g.Height(); y++ {
+			origins:
+				for x := 0; x+o.shape.Width() <= g.Width(); x++ {
+					origin := grid.Point{X: x, Y: y}
+					for _, c := range o.cells {
+						if g.Get(origin.Add(c)) != o.shape.Get(c) {
+							continue origins
+						}
+					}
+					if !yield(PatternMatch{Origin: origin, Shape: o.shape, cells: o.cells}) {
+						return
+					}
+				}
+			}
 		}
 	}
-	return matches
 }
 
-func SearchCrossWord(x int, y int, graph []string) int {
-	maxY := len(graph)
-	maxX := len(graph[0])
+// Count returns the number of times the pattern (in any orientation) is in g.
+func (p *Pattern) Count(g *grid.Grid[byte]) int {
+	total := 0
+	for range p.Find(g) {
+		total++
+	}
+	return total
+}
+
+// Two MAS's in the shape of an X, where either one can be written backwards.
+// The other orientations cover every combination of M's and S's.
+var XMas = MustPattern([]string{
+	"M.S",
+	".A.",
+	"M.S",
+}, '.')
 
-	// 'A' is on an edge, which is immediately invalid
-	if x == 0 || x == maxX-1 || y == 0 || y == maxY-1 {
-		return 0
+// Utility function to name the direction of a match, like NE for up and right
+func DirectionName(d grid.Point) string {
+	name := ""
+	if d.Y < 0 {
+		name += "N"
+	} else if d.Y > 0 {
+		name += "S"
 	}
+	if d.X > 0 {
+		name += "E"
+	} else if d.X < 0 {
+		name += "W"
+	}
+	return name
+}
 
-	// We know that the corners of the X must be 2 M's and 2 S's,
-	// which has a total decimal value of 320. We can use this to know that
-	// we possibly have a match. Then we can check if at least 1 side has 2
-	// matching characters.
-	crossValue := int(graph[y-1][x-1]) + int(graph[y+1][x-1]) + int(graph[y-1][x+1]) + int(graph[y+1][x+1])
-	if crossValue == 320 {
-		if graph[y-1][x-1] == graph[y-1][x+1] || graph[y-1][x-1] == graph[y+1][x-1] {
-			return 1
+// Prints the location and direction of every match for each of the words.
+// The puzzle is read as runes, so words can have any (multi-byte) characters.
+func Find(data []string, words []string, directions string) error {
+	var dirs []grid.Point
+	switch directions {
+	case "all":
+		dirs = grid.Surrounding
+	case "orthogonal":
+		dirs = grid.Orthogonal
+	case "diagonal":
+		dirs = grid.Diagonal
+	default:
+		return fmt.Errorf("unknown directions %q (must be all, orthogonal or diagonal)", directions)
+	}
+
+	puzzle := grid.FromRunes(data)
+	for _, word := range words {
+		matches := []Match{}
+		for m := range SearchWord(puzzle, []rune(word), dirs) {
+			matches = append(matches, m)
+		}
+		log.Printf("%s: %d matches", word, len(matches))
+		for _, m := range matches {
+			log.Printf("  (%d,%d) %s", m.Start.X, m.Start.Y, DirectionName(m.Dir))
 		}
 	}
+	return nil
+}
+
+// Prints the location and orientation of every match for a pattern, given as
+// rows separated by / with . as a wildcard.
+func FindPattern(data []string, pattern string) error {
+	p, err := NewPattern(strings.Split(pattern, "/"), '.')
+	if err != nil {
+		return err
+	}
+
+	matches := []PatternMatch{}
+	for m := range p.Find(grid.FromLines(data)) {
+		matches = append(matches, m)
+	}
+	log.Printf("%s: %d matches", pattern, len(matches))
+	for _, m := range matches {
+		shape := m.Shape.Render(func(_ grid.Point, c byte) rune { return rune(c) })
+		log.Printf("  (%d,%d) %s", m.Origin.X, m.Origin.Y, strings.ReplaceAll(strings.TrimSpace(shape), "\n", "/"))
+	}
+	return nil
+}
 
-	return 0
+// Exports a still image of the word search with every XMAS crossed out.
+func Snapshot(data []string, path string) error {
+	graph := grid.FromLines(data)
+	frame := grid.Map(graph, func(_ grid.Point, c byte) rune { return rune(c) })
+
+	// Dim the letters so the words stand out on top of them
+	snapshot := render.NewSnapshot()
+	snapshot.Palette.Colors = map[rune]color.Color{
+		'X': color.RGBA{R: 0x33, G: 0x33, B: 0x44, A: 0xff},
+		'M': color.RGBA{R: 0x44, G: 0x44, B: 0x55, A: 0xff},
+		'A': color.RGBA{R: 0x55, G: 0x55, B: 0x66, A: 0xff},
+		'S': color.RGBA{R: 0x66, G: 0x66, B: 0x77, A: 0xff},
+	}
+	for m := range SearchWord(graph, []byte("XMAS"), grid.Surrounding) {
+		snapshot.AddPath(m.Points(), color.RGBA{R: 0xff, G: 0xff, B: 0x66, A: 0xff})
+	}
+	return snapshot.Save(path, frame)
 }
 
 func Part1(data []string) int {
 	total := 0
-	for y, line := range data {
-		for x, c := range line {
-			if c == 88 { // X
-				total += SearchWord(x, y, data)
-			}
-		}
+	for range SearchWord(grid.FromLines(data), []byte("XMAS"), grid.Surrounding) {
+		total++
 	}
 	return total
 }
 
 func Part2(data []string) int {
-	total := 0
-	for y, line := range data {
-		for x, c := range line {
-			if c == 65 { // A
-				total += SearchCrossWord(x, y, data)
-			}
-		}
-	}
-	return total
+	return XMas.Count(grid.FromLines(data))
 }
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/grid"
+)
+
+func TestNewPatternRaggedRows(t *testing.T) {
+	for _, pattern := range []string{"M.S/.A", ".A/M.S", "M.S//M.S", ""} {
+		if _, err := NewPattern(strings.Split(pattern, "/"), '.'); err == nil {
+			t.Errorf("NewPattern(%q) should fail since the rows aren't the same width", pattern)
+		}
+	}
+}
+
+func TestPatternCount(t *testing.T) {
+	puzzle := grid.FromLines([]string{
+		"M.S.M",
+		".A.A.",
+		"M.S.M",
+	})
+	if got := XMas.Count(puzzle); got != 2 {
+		t.Errorf("XMas.Count = %d, want 2", got)
+	}
+
+	// Symmetric patterns shouldn't be counted once per orientation
+	dot, err := NewPattern([]string{"A"}, '.')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := dot.Count(puzzle); got != 2 {
+		t.Errorf("single cell pattern Count = %d, want 2", got)
+	}
+}
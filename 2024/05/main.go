@@ -2,16 +2,32 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
 	"os"
 	"slices"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/order"
+)
+
+var (
+	explain = flag.Bool("explain", false, "report the broken rules and corrected order for each invalid update")
+	format  = flag.String("format", "text", "format for --explain output (text or json)")
 )
 
 func main() {
+	flag.Parse()
 	log.SetOutput(os.Stdout) // Log to stdout instead of stderr
+	if *explain && *format == "json" {
+		log.SetOutput(os.Stderr) // Keep stdout as valid JSON
+	}
 	updates, rules := GetInputData(os.Stdin)
 
 	p1Start := time.Now()
@@ -20,15 +36,24 @@ func main() {
 	log.Printf("Part 1: %d (%s)", part1, p1End)
 
 	p2Start := time.Now()
-	part2 := Part2(updates, rules)
+	part2, err := Part2(updates, rules)
+	if err != nil {
+		log.Fatal(err)
+	}
 	p2End := time.Since(p2Start)
 	log.Printf("Part 2: %d (%s)", part2, p2End)
 	log.Printf("Total time: %s", p1End+p2End)
+
+	if *explain {
+		if err := Explain(os.Stdout, updates, rules, *format); err != nil {
+			log.Fatal(err)
+		}
+	}
 }
 
 // Utility function to read entire input file
-func GetInputData(file *os.File) ([][]int, [][]int) {
-	rules := [][]int{}
+func GetInputData(file *os.File) ([][]int, *order.Rules[int]) {
+	rules := order.NewRules[int]()
 	updates := [][]int{}
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
@@ -38,7 +63,8 @@ func GetInputData(file *os.File) ([][]int, [][]int) {
 		if line == "" { // blank
 			continue
 		} else if strings.Contains(line, "|") { // rule
-			rules = append(rules, ParseInts(strings.Split(line, "|")))
+			pages := ParseInts(strings.Split(line, "|"))
+			rules.Add(pages[0], pages[1])
 		} else { // updates
 			updates = append(updates, ParseInts(strings.Split(line, ",")))
 		}
@@ -59,101 +85,137 @@ func ParseInts(a []string) []int {
 	return nums
 }
 
-// Utility function that will insert an element at a given position,
-// shifting the remaining elements to the right.
-func insertInt[T any](array []T, value T, index int) []T {
-	return append(array[:index], append([]T{value}, array[index:]...)...)
+// Reorders the pages so they don't break any of the rules. If the rules
+// between the pages have a cycle then there is no valid order, and an
+// *order.CycleError is returned.
+func FixOrder(pages []int, rules *order.Rules[int]) ([]int, error) {
+	sorted, _, err := fixOrder(pages, rules)
+	return sorted, err
 }
 
-// Utility function to remove an element from an array
-func removeInt[T any](array []T, index int) []T {
-	return append(array[:index], array[index+1:]...)
+// Same as FixOrder, but also reports whether the comparator sort was used
+func fixOrder(pages []int, rules *order.Rules[int]) ([]int, bool, error) {
+	// If the rules between these pages are a total order then we can just
+	// sort with them directly. Proving that up front is O(n^3) though, so
+	// instead we just try it and check the result, which is only O(n^2).
+	// Otherwise we fall back to topologically sorting the pages, which only
+	// needs the rules to not have a cycle.
+	sorted := slices.Clone(pages)
+	slices.SortFunc(sorted, rules.Compare)
+	if rules.IsOrdered(sorted) {
+		return sorted, true, nil
+	}
+
+	sorted, err := rules.Sort(pages)
+	return sorted, false, err
 }
 
-// Utility function to "move" an element in an array to a new position,
-// shifting the remaining elements to the right.
-func moveElement[T any](array []T, srcIndex int, dstIndex int) []T {
-	value := array[srcIndex]
-	return insertInt(removeInt(array, srcIndex), value, dstIndex)
+// Details of why an update is invalid and how it was fixed
+type Explanation struct {
+	Update       int                    `json:"update"` // Position in the list of updates, starting from 1
+	Pages        []int                  `json:"pages"`
+	Violations   []order.Violation[int] `json:"violations"`
+	Corrected    []int                  `json:"corrected"`
+	Method       string                 `json:"method"`                    // How the pages were sorted (comparator or topological)
+	NotTotal     *order.OrderError[int] `json:"not_total_order,omitempty"` // Why the rules aren't a total order on the pages, if they aren't
+	MiddleBefore int                    `json:"middle_before"`
+	MiddleAfter  int                    `json:"middle_after"`
 }
 
-func Part1(updates [][]int, rules [][]int) int {
-	total := 0
+// Writes an explanation for every invalid update as either text or JSON
+func Explain(w io.Writer, updates [][]int, rules *order.Rules[int], format string) error {
+	explanations := []Explanation{}
+	for i, pages := range updates {
+		violations := rules.Violations(pages)
+		if len(violations) == 0 {
+			continue
+		}
 
-updatesLoop:
-	// Loop over each upate, and label it so we can jump out of it when needed
-	for _, pages := range updates {
-		// Check each "page"
-		for i, page := range pages {
-			// Check page against each "rule"
-			for _, rule := range rules {
-				// Skip rules that don't apply to this page
-				if rule[0] != page {
-					continue
-				}
-
-				// Check if target page exsits in the page list
-				// If not, then it's ok. If it does, it must be after
-				// our current position in the array.
-				index := slices.Index(pages, rule[1])
-				if index != -1 && index < i {
-					// This list of pages is invalid, so we can stop here and
-					// move to the next list.
-					continue updatesLoop
-				}
+		corrected, comparator, err := fixOrder(pages, rules)
+		if err != nil {
+			return fmt.Errorf("update %d: %w", i+1, err)
+		}
+		e := Explanation{
+			Update:       i + 1,
+			Pages:        pages,
+			Violations:   violations,
+			Corrected:    corrected,
+			Method:       "topological",
+			MiddleBefore: pages[(len(pages)-1)/2],
+			MiddleAfter:  corrected[(len(corrected)-1)/2],
+		}
+		if comparator {
+			e.Method = "comparator"
+		}
+
+		// Find the smallest set of pages that shows why the comparator can't
+		// be trusted, which is too slow to do outside of --explain
+		var notTotal *order.OrderError[int]
+		if errors.As(rules.ValidateTotalOrder(pages), &notTotal) {
+			e.NotTotal = notTotal
+		}
+		explanations = append(explanations, e)
+	}
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(explanations)
+	case "text":
+		for _, e := range explanations {
+			fmt.Fprintf(w, "Update %d: %s\n", e.Update, JoinInts(e.Pages))
+			for _, v := range e.Violations {
+				fmt.Fprintf(w, "  broke %d|%d: %d is at position %d, after %d at position %d\n",
+					v.Before, v.After, v.Before, v.BeforeIndex, v.After, v.AfterIndex)
+			}
+			fmt.Fprintf(w, "  corrected: %s\n", JoinInts(e.Corrected))
+			if e.NotTotal != nil {
+				fmt.Fprintf(w, "  sorted with: %s (%s)\n", e.Method, e.NotTotal)
+			} else {
+				fmt.Fprintf(w, "  sorted with: %s\n", e.Method)
 			}
+			fmt.Fprintf(w, "  middle page: %d -> %d\n", e.MiddleBefore, e.MiddleAfter)
 		}
+		return nil
+	}
+	return fmt.Errorf("unknown explain format %q", format)
+}
 
-		// Add value of middle element to total
-		total += pages[(len(pages)-1)/2]
+// Utility function to join ints back together with commas
+func JoinInts(nums []int) string {
+	strs := make([]string, len(nums))
+	for i, num := range nums {
+		strs[i] = strconv.Itoa(num)
 	}
-	return total
+	return strings.Join(strs, ",")
 }
 
-func Part2(updates [][]int, rules [][]int) int {
+func Part1(updates [][]int, rules *order.Rules[int]) int {
 	total := 0
-
 	for _, pages := range updates {
-		// Check each update and track if it has been updated
-		updated := false
-
-	pagesLoop:
-		// Loop over the pages, and label it so we can jump out of it when needed
-		// This is essentially just bubble sort, but it's actually pretty fast, doesn't
-		// cause too many swap operations, and doesn't encounter any cycle issues.
-		for i := 0; i < len(pages)-1; i++ {
-			page := pages[i]
-
-			// Check page against each "rule"
-			for _, rule := range rules {
-				// Skip rules that don't apply to this page
-				if rule[1] != page {
-					continue
-				}
-
-				// Check if target page exsits in the page list
-				// If not, then it's ok. If it does, check if it appears before
-				// the page, which would break the rule. If so, move the page in front
-				// of it and re-check the pages again at the same position.
-				index := slices.Index(pages, rule[0])
-				if index == -1 || index < i {
-					continue
-				} else if index >= i {
-					updated = true
-					pages = moveElement(pages, index, i)
-
-					// Increase the page index counter so we "recheck" the pages at this
-					// point again for more breaking rules, after things have shifted around.
-					i--
-					continue pagesLoop
-				}
-			}
-		}
-
-		// Add value of middle element to total if the page was updated
-		if updated {
+		// Add value of middle element to total if the pages are already in order
+		if rules.IsOrdered(pages) {
 			total += pages[(len(pages)-1)/2]
 		}
 	}
 	return total
 }
+
+func Part2(updates [][]int, rules *order.Rules[int]) (int, error) {
+	total := 0
+	for i, pages := range updates {
+		if rules.IsOrdered(pages) {
+			continue
+		}
+
+		sorted, err := FixOrder(pages, rules)
+		if err != nil {
+			return 0, fmt.Errorf("update %d: %w", i+1, err)
+		}
+
+		// Add value of middle element to total since the page was updated
+		total += sorted[(len(sorted)-1)/2]
+	}
+	return total, nil
+}
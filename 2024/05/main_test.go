@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/order"
+)
+
+func TestPart2Cycle(t *testing.T) {
+	rules := order.NewRules[int]()
+	rules.Add(1, 2)
+	rules.Add(2, 3)
+	rules.Add(3, 1)
+
+	_, err := Part2([][]int{{1, 2, 3}}, rules)
+	var cycle *order.CycleError[int]
+	if !errors.As(err, &cycle) {
+		t.Fatalf("Part2 returned %v, want a CycleError", err)
+	}
+	if len(cycle.Cycle) != 4 || cycle.Cycle[0] != cycle.Cycle[3] {
+		t.Errorf("cycle = %v, want 3 pages with the first repeated at the end", cycle.Cycle)
+	}
+}
+
+func TestFixOrderPartialRules(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	for range 500 {
+		// Random rules that all agree with one hidden order, but leave a lot
+		// of pairs without a rule so they often aren't a total order
+		hidden := rng.Perm(10)
+		rules := order.NewRules[int]()
+		for i := range hidden {
+			for j := i + 1; j < len(hidden); j++ {
+				if rng.Intn(3) == 0 {
+					rules.Add(hidden[i], hidden[j])
+				}
+			}
+		}
+
+		pages := rng.Perm(10)[:1+rng.Intn(9)]
+		sorted, err := FixOrder(pages, rules)
+		if err != nil {
+			t.Fatalf("FixOrder(%v) failed: %v", pages, err)
+		}
+		if !rules.IsOrdered(sorted) || !slices.Equal(slices.Sorted(slices.Values(sorted)), slices.Sorted(slices.Values(pages))) {
+			t.Errorf("FixOrder(%v) = %v, which isn't a valid order of the same pages", pages, sorted)
+		}
+	}
+}
+
+func TestExplainNotTotalOrder(t *testing.T) {
+	// 1 and 3 don't have a rule between them
+	rules := order.NewRules[int]()
+	rules.Add(1, 2)
+	rules.Add(3, 2)
+	updates := [][]int{{2, 1, 3}}
+
+	var out bytes.Buffer
+	if err := Explain(&out, updates, rules, "json"); err != nil {
+		t.Fatal(err)
+	}
+	explanations := []Explanation{}
+	if err := json.Unmarshal(out.Bytes(), &explanations); err != nil {
+		t.Fatal(err)
+	}
+	if len(explanations) != 1 || explanations[0].NotTotal == nil {
+		t.Fatalf("explanation is missing why the rules aren't a total order: %s", out.String())
+	}
+	if e := explanations[0].NotTotal; e.Property != "total" || !slices.Equal(e.Items, []int{1, 3}) {
+		t.Errorf("not a total order because %s %v, want total [1 3]", e.Property, e.Items)
+	}
+
+	out.Reset()
+	if err := Explain(&out, updates, rules, "text"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "rules are not a strict total order (total): 1, 3") {
+		t.Errorf("text explanation is missing why the rules aren't a total order:\n%s", out.String())
+	}
+}
@@ -2,11 +2,22 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"slices"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/bitset"
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/grid"
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/render"
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/tui"
 )
 
 type Coordinate struct {
@@ -15,25 +26,75 @@ type Coordinate struct {
 	Y     int
 }
 
+var (
+	visualize = flag.String("visualize", "", "export an animation of the patrol (.gif or .cast)")
+	snapshot  = flag.String("snapshot", "", "export an image of the visited tiles and obstructions (.png or .svg)")
+	interact  = flag.Bool("interactive", false, "step through the patrol in the terminal, toggling obstructions")
+	explain   = flag.Bool("explain", false, "print the loop each obstruction causes, and a map of the obstructions")
+	multiple  = flag.Bool("multiple", false, "allow several guards on the map, simulating each one independently")
+	workers   = flag.Int("workers", runtime.NumCPU(), "number of workers used to check obstructions")
+)
+
 func main() {
+	flag.Parse()
 	log.SetOutput(os.Stdout) // Log to stdout instead of stderr
-	start, walls, size := GetInputData(os.Stdin)
+	guards, walls, size, err := GetInputData(os.Stdin, *multiple)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// These all show a single patrol, so there's no way to tell the guards apart
+	if len(guards) > 1 && (*interact || *visualize != "" || *snapshot != "") {
+		log.Fatalf("--interactive, --visualize and --snapshot only work with a single guard, found %d", len(guards))
+	}
+
+	// Each guard patrols on their own, as if the others weren't there
+	for i, start := range guards {
+		if len(guards) > 1 {
+			log.Printf("Guard %d at [x=%d, y=%d] facing %c", i+1, start.X, start.Y, start.value)
+		}
+
+		p1Start := time.Now()
+		part1, err := Part1(start, walls, size)
+		if err != nil {
+			log.Fatal(err)
+		}
+		p1End := time.Since(p1Start)
+		log.Printf("Part 1: %d (%s)", part1, p1End)
 
-	p1Start := time.Now()
-	part1 := Part1(start, walls, size)
-	p1End := time.Since(p1Start)
-	log.Printf("Part 1: %d (%s)", part1, p1End)
+		p2Start := time.Now()
+		part2, err := Part2(start, walls, size)
+		if err != nil {
+			log.Fatal(err)
+		}
+		p2End := time.Since(p2Start)
+		log.Printf("Part 2: %d (%s)", part2, p2End)
+		log.Printf("Total time: %s", p1End+p2End)
+	}
 
-	p2Start := time.Now()
-	part2 := Part2(start, walls, size)
-	p2End := time.Since(p2Start)
-	log.Printf("Part 2: %d (%s)", part2, p2End)
-	log.Printf("Total time: %s", p1End+p2End)
+	if *interact {
+		if err := tui.Run(NewPatrolStepper(guards[0], walls, size)); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *visualize != "" {
+		if err := Visualize(guards[0], walls, size, *visualize); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Saved visualization to %s", *visualize)
+	}
+	if *snapshot != "" {
+		if err := Snapshot(guards[0], walls, size, *snapshot); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Saved snapshot to %s", *snapshot)
+	}
 }
 
 // Utility function to read entire input file
-func GetInputData(file *os.File) (Coordinate, []Coordinate, []int) {
-	start := Coordinate{}
+// The guard can start facing any direction, and there must be exactly one of
+// them unless multiple is set.
+func GetInputData(file io.Reader, multiple bool) ([]Coordinate, []Coordinate, []int, error) {
+	guards := []Coordinate{}
 	walls := []Coordinate{}
 
 	lc := 0
@@ -44,152 +105,109 @@ func GetInputData(file *os.File) (Coordinate, []Coordinate, []int) {
 		width = len(line)
 
 		// Parse from grid
-		// We only care about walls and starting position
+		// We only care about walls and starting positions
 		for i, char := range line {
 			if char == '#' {
 				walls = append(walls, Coordinate{X: i, Y: lc, value: char})
-			} else if char == '^' {
-				start = Coordinate{X: i, Y: lc, value: char}
+			} else if _, ok := grid.ParseDirection(char); ok {
+				guard := Coordinate{X: i, Y: lc, value: char}
+				if len(guards) > 0 && !multiple {
+					return nil, nil, nil, fmt.Errorf(
+						"found a second guard at [x=%d, y=%d] (first at [x=%d, y=%d]), use --multiple to simulate both",
+						guard.X, guard.Y, guards[0].X, guards[0].Y)
+				}
+				guards = append(guards, guard)
 			}
 		}
 		lc++
 	}
-	return start, walls, []int{width, lc}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+	if len(guards) == 0 {
+		return nil, nil, nil, errors.New("no guard (^ > v <) found on the map")
+	}
+	return guards, walls, []int{width, lc}, nil
 }
 
-func FindNearestWall(pos Coordinate, walls []Coordinate) (Coordinate, bool) {
-	nearest := Coordinate{X: -1, Y: -1, value: '.'}
-
-	// Check each wall for ones that apply to the move, skipping those
-	// that are not in the path of the guard.
-	// We keep track of the nearest wall because that is the first one we will run
-	// into in that direction.
+// Indexes the walls by row and column, so finding the next wall in the guard's
+// path is a binary search instead of a scan over every wall.
+func NewWallIndex(walls []Coordinate) *grid.SparseGrid[rune] {
+	index := grid.NewSparse('.')
 	for _, wall := range walls {
-		if pos.value == '^' { // North
-			if wall.X != pos.X || wall.Y > pos.Y {
-				continue
-			}
-
-			if nearest.value == '.' {
-				nearest = wall
-			} else if wall.Y > nearest.Y {
-				nearest = wall
-			}
-		} else if pos.value == '>' { // East
-			if wall.Y != pos.Y || wall.X < pos.X {
-				continue
-			}
-
-			if nearest.value == '.' {
-				nearest = wall
-			} else if wall.X < nearest.X {
-				nearest = wall
-			}
-		} else if pos.value == 'v' { // South
-			if wall.X != pos.X || wall.Y < pos.Y {
-				continue
-			}
-
-			if nearest.value == '.' {
-				nearest = wall
-			} else if wall.Y < nearest.Y {
-				nearest = wall
-			}
-		} else if pos.value == '<' { // West
-			if wall.Y != pos.Y || wall.X > pos.X {
-				continue
-			}
+		index.Set(grid.Point{X: wall.X, Y: wall.Y}, wall.value)
+	}
+	return index
+}
 
-			if nearest.value == '.' {
-				nearest = wall
-			} else if wall.X > nearest.X {
-				nearest = wall
-			}
-		}
+func FindNearestWall(pos Coordinate, walls *grid.SparseGrid[rune]) (Coordinate, bool) {
+	// The nearest wall in the direction the guard is facing is the first one
+	// they will run into.
+	dir, _ := grid.ParseDirection(pos.value)
+	wall, found := walls.Nearest(grid.Point{X: pos.X, Y: pos.Y}, dir)
+	if !found {
+		return Coordinate{X: -1, Y: -1, value: '.'}, false
 	}
+	return Coordinate{X: wall.X, Y: wall.Y, value: walls.Get(wall)}, true
+}
 
-	return nearest, nearest.value != '.'
+// Moves the guard up to the wall in front of them and rotates 90 degrees
+func MoveToWall(pos *Coordinate, wall Coordinate) {
+	if pos.value == '^' { // North
+		pos.value = '>'
+		pos.Y = wall.Y + 1
+	} else if pos.value == '>' { // East
+		pos.value = 'v'
+		pos.X = wall.X - 1
+	} else if pos.value == 'v' { // South
+		pos.value = '<'
+		pos.Y = wall.Y - 1
+	} else if pos.value == '<' { // West
+		pos.value = '^'
+		pos.X = wall.X + 1
+	}
 }
 
-func WalkToWall(pos *Coordinate, wall Coordinate, seen *[]int) {
+func WalkToWall(pos *Coordinate, wall Coordinate, width int, seen *bitset.BitSet) {
 	if pos.value == '^' { // North
 		for i := wall.Y + 1; i < pos.Y; i++ {
-			coord := pos.X | i<<16
-			if !slices.Contains(*seen, coord) {
-				*seen = append(*seen, coord)
-			}
+			seen.Add(pos.X + i*width)
 		}
-
-		// Rotate 90
-		pos.value = '>'
-		pos.Y = wall.Y + 1
 	} else if pos.value == '>' { // East
 		for i := pos.X; i < wall.X; i++ {
-			coord := i | pos.Y<<16
-			if !slices.Contains(*seen, coord) {
-				*seen = append(*seen, coord)
-			}
+			seen.Add(i + pos.Y*width)
 		}
-
-		// Rotate 90
-		pos.value = 'v'
-		pos.X = wall.X - 1
 	} else if pos.value == 'v' { // South
 		for i := pos.Y; i < wall.Y; i++ {
-			coord := pos.X | i<<16
-			if !slices.Contains(*seen, coord) {
-				*seen = append(*seen, coord)
-			}
+			seen.Add(pos.X + i*width)
 		}
-
-		// Rotate 90
-		pos.value = '<'
-		pos.Y = wall.Y - 1
 	} else if pos.value == '<' { // West
 		for i := wall.X + 1; i < pos.X; i++ {
-			coord := i | pos.Y<<16
-			if !slices.Contains(*seen, coord) {
-				*seen = append(*seen, coord)
-			}
+			seen.Add(i + pos.Y*width)
 		}
-
-		// Rotate 90
-		pos.value = '^'
-		pos.X = wall.X + 1
 	}
+	MoveToWall(pos, wall)
 }
 
-func WalkToEdge(pos *Coordinate, maxX int, maxY int, seen *[]int) {
+func WalkToEdge(pos *Coordinate, maxX int, maxY int, seen *bitset.BitSet) {
 	if pos.value == '^' { // North
 		for i := 0; i < pos.Y; i++ {
-			coord := pos.X | i<<16
-			if !slices.Contains(*seen, coord) {
-				*seen = append(*seen, coord)
-			}
+			seen.Add(pos.X + i*maxX)
 		}
 		pos.Y = 0 // Move to edge
 	} else if pos.value == '>' { // East
 		for i := pos.X; i < maxX; i++ {
-			coord := i | pos.Y<<16
-			if !slices.Contains(*seen, coord) {
-				*seen = append(*seen, coord)
-			}
+			seen.Add(i + pos.Y*maxX)
 		}
 		pos.X = maxX - 1 // Move to edge
 	} else if pos.value == 'v' { // South
 		for i := pos.Y; i < maxY; i++ {
-			coord := pos.X | i<<16
-			if !slices.Contains(*seen, coord) {
-				*seen = append(*seen, coord)
-			}
+			seen.Add(pos.X + i*maxX)
 		}
 		pos.Y = maxY - 1 // Move to edge
 	} else if pos.value == '<' { // West
 		for i := 0; i < pos.X; i++ {
-			coord := i | pos.Y<<16
-			if !slices.Contains(*seen, coord) {
-				*seen = append(*seen, coord)
-			}
+			seen.Add(i + pos.Y*maxX)
 		}
 		pos.X = 0 // Move to edge
 	}
@@ -209,210 +227,404 @@ func DirectionToInt(coord Coordinate) int {
 	return 0
 }
 
-func PrintGraph(pos Coordinate, walls []Coordinate, size []int, loops []Coordinate) {
-	for i := 0; i < size[0]; i++ {
-		for j := 0; j < size[1]; j++ {
-			if slices.Contains(walls, Coordinate{X: j, Y: i, value: '#'}) {
-				fmt.Printf("#")
-			} else if slices.Contains(loops, Coordinate{X: j, Y: i, value: 'O'}) {
-				fmt.Printf("O")
-			} else if pos.X == j && pos.Y == i {
-				fmt.Printf("%s", string(pos.value))
-			} else {
-				fmt.Printf(".")
-			}
+// Builds a character map of the walls that the guard can walk across.
+func BuildMap(walls []Coordinate, size []int) *grid.Grid[rune] {
+	frame := grid.New[rune](size[0], size[1])
+	frame.Fill('.')
+	for _, wall := range walls {
+		frame.Set(grid.Point{X: wall.X, Y: wall.Y}, '#')
+	}
+	return frame
+}
+
+// Draws the map with the guard's starting position and every obstruction (O)
+// that would trap them in a loop.
+func ObstructionMap(pos Coordinate, walls []Coordinate, size []int, loops []Coordinate) string {
+	frame := BuildMap(walls, size)
+	frame.Set(grid.Point{X: pos.X, Y: pos.Y}, pos.value)
+	for _, obstruction := range loops {
+		frame.Set(grid.Point{X: obstruction.X, Y: obstruction.Y}, 'O')
+	}
+	return frame.Render(func(_ grid.Point, c rune) rune { return c })
+}
+
+// Reasons a simulation can stop
+type Outcome int
+
+const (
+	Running Outcome = iota
+	Exited          // Guard walked off the edge of the map
+	Looped          // Guard is stuck walking in a loop
+)
+
+// Position and heading of the guard at a single point in time
+type State struct {
+	Pos     grid.Point
+	Heading grid.Direction
+}
+
+func (s State) String() string {
+	return fmt.Sprintf("[x=%d, y=%d] %c", s.Pos.X, s.Pos.Y, s.Heading.Rune())
+}
+
+// Steps through the guard's patrol one move (or turn) at a time, keeping
+// track of every state so we know when the guard has started looping.
+// This is a lot slower than jumping between walls, but is much easier to
+// follow when debugging.
+type Simulator struct {
+	Map     *grid.Grid[rune] // Walls (#) and obstructions (O) block the guard
+	State   State
+	History []State
+	Outcome Outcome
+
+	seen map[State]int // State -> step it was first seen at
+	loop int           // Step that the loop started at
+}
+
+func NewSimulator(m *grid.Grid[rune], pos Coordinate) *Simulator {
+	heading, _ := grid.ParseDirection(pos.value)
+	state := State{Pos: grid.Point{X: pos.X, Y: pos.Y}, Heading: heading}
+	return &Simulator{
+		Map:     m,
+		State:   state,
+		History: []State{state},
+		seen:    map[State]int{state: 0},
+	}
+}
+
+// Moves the guard forward one tile, or turns them 90 degrees if they are
+// blocked. Returns false once the guard has left the map or started looping.
+func (s *Simulator) Step() bool {
+	if s.Outcome != Running {
+		return false
+	}
+
+	next := s.State.Pos.Add(s.State.Heading.Delta())
+	c, ok := s.Map.Lookup(next)
+	if !ok {
+		s.Outcome = Exited
+		return false
+	} else if c == '#' || c == 'O' {
+		s.State.Heading = s.State.Heading.Right()
+	} else {
+		s.State.Pos = next
+	}
+
+	// Being in the exact same state twice means the guard will keep repeating
+	// the same steps forever.
+	if step, ok := s.seen[s.State]; ok {
+		s.Outcome = Looped
+		s.loop = step
+		return false
+	}
+	s.seen[s.State] = len(s.History)
+	s.History = append(s.History, s.State)
+	return true
+}
+
+// Steps the guard until they leave the map or start looping.
+func (s *Simulator) Run() Outcome {
+	for s.Step() {
+	}
+	return s.Outcome
+}
+
+// Number of steps taken so far
+func (s *Simulator) Steps() int {
+	return len(s.History) - 1
+}
+
+// The states that the guard repeats forever, if they are looping
+func (s *Simulator) Cycle() []State {
+	if s.Outcome != Looped {
+		return nil
+	}
+	return s.History[s.loop:]
+}
+
+// Describes why the simulation stopped (or where it is at)
+func (s *Simulator) Reason() string {
+	switch s.Outcome {
+	case Exited:
+		return fmt.Sprintf("exited the map at %s after %d steps", s.State, s.Steps())
+	case Looped:
+		return fmt.Sprintf("entered a loop at %s after %d steps (cycle of %d steps)",
+			s.History[s.loop], s.loop, len(s.Cycle()))
+	}
+	return fmt.Sprintf("still walking at %s after %d steps", s.State, s.Steps())
+}
+
+// Walks the guard one tile at a time across the map until they leave it,
+// marking each visited tile with an X. The guard is drawn at their current
+// position while step is called, which can be nil.
+func Patrol(frame *grid.Grid[rune], pos Coordinate, step func()) {
+	sim := NewSimulator(frame, pos)
+	for {
+		frame.Set(sim.State.Pos, sim.State.Heading.Rune())
+		if step != nil {
+			step()
+		}
+		frame.Set(sim.State.Pos, 'X')
+
+		if !sim.Step() {
+			return
+		}
+	}
+}
+
+// Lets the TUI step through the patrol, re-running it whenever an obstruction
+// is toggled.
+type PatrolStepper struct {
+	start Coordinate
+	base  *grid.Grid[rune] // Walls and any toggled obstructions
+	sim   *Simulator
+}
+
+func NewPatrolStepper(pos Coordinate, walls []Coordinate, size []int) *PatrolStepper {
+	s := &PatrolStepper{start: pos, base: BuildMap(walls, size)}
+	s.run()
+	return s
+}
+
+func (s *PatrolStepper) run() {
+	s.sim = NewSimulator(s.base, s.start)
+	s.sim.Run()
+}
+
+func (s *PatrolStepper) Steps() int {
+	return s.sim.Steps()
+}
+
+func (s *PatrolStepper) Frame(step int) *grid.Grid[rune] {
+	frame := s.base.Clone()
+	for _, state := range s.sim.History[:step] {
+		frame.Set(state.Pos, 'X')
+	}
+	state := s.sim.History[step]
+	frame.Set(state.Pos, state.Heading.Rune())
+	return frame
+}
+
+func (s *PatrolStepper) Marker(step int) bool {
+	return step > 0 && s.sim.History[step].Heading != s.sim.History[step-1].Heading
+}
+
+func (s *PatrolStepper) Toggle(p grid.Point) bool {
+	// Walls can't be moved, and the guard is already standing on the start
+	if p == (grid.Point{X: s.start.X, Y: s.start.Y}) {
+		return false
+	}
+	switch s.base.Get(p) {
+	case '.':
+		s.base.Set(p, 'O')
+	case 'O':
+		s.base.Set(p, '.')
+	default:
+		return false
+	}
+	s.run()
+	return true
+}
+
+func (s *PatrolStepper) Status(step int) string {
+	if step == s.sim.Steps() {
+		return s.sim.Reason()
+	}
+	return fmt.Sprintf("walking at %s", s.sim.History[step])
+}
+
+// Describes why placing an obstruction traps the guard, listing each corner
+// of the loop they end up walking.
+func Explain(pos Coordinate, walls []Coordinate, size []int, obstruction Coordinate) string {
+	m := BuildMap(walls, size)
+	m.Set(grid.Point{X: obstruction.X, Y: obstruction.Y}, 'O')
+	sim := NewSimulator(m, pos)
+	sim.Run()
+
+	corners := []string{}
+	cycle := sim.Cycle()
+	for i, state := range cycle {
+		if i == 0 || state.Heading != cycle[i-1].Heading {
+			corners = append(corners, state.String())
 		}
-		fmt.Println()
 	}
-	fmt.Println()
+	return fmt.Sprintf("Obstruction at [x=%d, y=%d] %s: %s",
+		obstruction.X, obstruction.Y, sim.Reason(), strings.Join(corners, " -> "))
+}
+
+// Records each step of the patrol so it can be exported as an animation.
+func Visualize(pos Coordinate, walls []Coordinate, size []int, path string) error {
+	frame := BuildMap(walls, size)
+	recorder := render.NewRecorder()
+	Patrol(frame, pos, func() { recorder.Capture(frame) })
+	recorder.Capture(frame)
+	return recorder.Save(path)
+}
+
+// Exports a still image of every tile the guard visits, along with the
+// positions where an obstruction would cause a loop.
+func Snapshot(pos Coordinate, walls []Coordinate, size []int, path string) error {
+	frame := BuildMap(walls, size)
+	Patrol(frame, pos, nil)
+	frame.Set(grid.Point{X: pos.X, Y: pos.Y}, pos.value)
+
+	loops, err := FindObstructions(pos, walls, size, *workers)
+	if err != nil {
+		return err
+	}
+	obstructions := []grid.Point{}
+	for _, loop := range loops {
+		obstructions = append(obstructions, grid.Point{X: loop.X, Y: loop.Y})
+	}
+
+	snapshot := render.NewSnapshot()
+	snapshot.AddCells(obstructions, snapshot.Palette.Color('O'))
+	return snapshot.Save(path, frame)
 }
 
-func Part1(pos Coordinate, walls []Coordinate, size []int) int {
-	// We can cram the smaller X,Y coordinates into a single int
-	// with some bitshift, which is about 2x faster than using a struct.
-	seen := []int{pos.X | pos.Y<<16}
+// Returned when the guard's own patrol is a loop, so they never leave the map
+var ErrNoExit = errors.New("guard never leaves the map")
+
+// Walks the guard's patrol until they leave the map, returning every
+// tile that was visited along the way.
+func WalkPath(pos Coordinate, walls *grid.SparseGrid[rune], size []int) (*bitset.BitSet, error) {
+	// Each tile gets a single bit, indexed in row-major order
+	seen := bitset.New(size[0] * size[1])
+	seen.Add(pos.X + pos.Y*size[0])
 
+	// Same as IsLoop, hitting a wall from the same direction twice means the
+	// guard is going around in circles.
+	turns := bitset.NewStateSet(size[0] * size[1])
+	start := pos
 	for {
 		// If we found a wall then track the tiles we have not seen yet
 		// that are between the current position and the wall.
 		// If we don't find a wall, then we will walk to the edge of the map.
 		wall, found := FindNearestWall(pos, walls)
 		if found {
-			WalkToWall(&pos, wall, &seen)
+			if !turns.Add(wall.X+wall.Y*size[0], DirectionToInt(pos)-1) {
+				return nil, fmt.Errorf("patrol from [x=%d, y=%d]: %w", start.X, start.Y, ErrNoExit)
+			}
+			WalkToWall(&pos, wall, size[0], seen)
 		} else {
-			WalkToEdge(&pos, size[0], size[1], &seen)
+			WalkToEdge(&pos, size[0], size[1], seen)
 			break
 		}
 	}
-	return len(seen)
+	return seen, nil
 }
 
-func Part2(pos Coordinate, walls []Coordinate, size []int) int {
-	seen := []int{pos.X | pos.Y<<16}
-	hitWalls := []int{}
-	loops := []Coordinate{}
+// Reusable buffers for checking obstructions, so each worker only needs to
+// allocate them once instead of for every candidate.
+type LoopChecker struct {
+	walls  *grid.SparseGrid[rune]
+	states *bitset.StateSet
+	width  int
+}
 
-	for {
-		// If we found a wall then track the tiles we have not seen yet
-		// that are between the current position and the wall.
-		// If we don't find a wall, then we will walk to the edge of the map.
-		wall, found := FindNearestWall(pos, walls)
+func NewLoopChecker(walls []Coordinate, size []int) *LoopChecker {
+	return &LoopChecker{
+		walls:  NewWallIndex(walls),
+		states: bitset.NewStateSet(size[0] * size[1]),
+		width:  size[0],
+	}
+}
 
-		// This works for the test input but is to LOW for the real one.
-		// The logic is similiar to Part 1, but while "walking" between walls we
-		// check if there is an adjacent wall to our right that we have hit before
-		// in the same direction, which would cause a loop to occur.
-		// There are probably other non-hit walls that could cause loops?
-		// Possibly better to check walked paths w/ direction to see if we enter the
-		// the same state?
-		if found {
-			hitWalls = append(hitWalls, DirectionToInt(pos)|wall.X<<8|wall.Y<<16)
-			if pos.value == '^' { // North
-				for i := wall.Y + 1; i < pos.Y; i++ {
-					loc := Coordinate{X: pos.X, Y: i, value: '>'}
-					w, f := FindNearestWall(loc, walls)
-					if f && slices.Contains(hitWalls, DirectionToInt(loc)|w.X<<8|w.Y<<16) {
-						loc.Y-- // Place wall in "front" of current location
-						log.Printf("Loop at [x=%d, y=%d]", loc.X, loc.Y)
-						loops = append(loops, Coordinate{X: loc.X, Y: loc.Y, value: 'O'})
-					}
-
-					coord := pos.X | i<<16
-					if !slices.Contains(seen, coord) {
-						seen = append(seen, coord)
-					}
-				}
+// Simulates the guard's patrol with an extra obstruction on the map, and
+// reports whether the guard gets stuck walking in a loop.
+func (c *LoopChecker) IsLoop(pos Coordinate, obstruction Coordinate) bool {
+	// Patch the obstruction into the index just for this simulation. If
+	// there's already a wall there then leave it alone, otherwise cleaning up
+	// afterwards would delete the real wall.
+	p := grid.Point{X: obstruction.X, Y: obstruction.Y}
+	if !c.walls.Has(p) {
+		c.walls.Set(p, obstruction.value)
+		defer c.walls.Delete(p)
+	}
+	c.states.Clear()
 
-				// Rotate 90
-				pos.value = '>'
-				pos.Y = wall.Y + 1
-			} else if pos.value == '>' { // East
-				for i := pos.X; i < wall.X; i++ {
-					loc := Coordinate{X: i, Y: pos.Y, value: 'v'}
-					w, f := FindNearestWall(loc, walls)
-					if f && slices.Contains(hitWalls, DirectionToInt(loc)|w.X<<8|w.Y<<16) {
-						log.Printf("Loop at [x=%d, y=%d]", loc.X, loc.Y)
-						loc.X++ // Place wall in "front" of current location
-						loops = append(loops, Coordinate{X: loc.X, Y: loc.Y, value: 'O'})
-					}
-
-					coord := i | pos.Y<<16
-					if !slices.Contains(seen, coord) {
-						seen = append(seen, coord)
-					}
-				}
+	// The guard is in a loop once they hit the same wall from the same
+	// direction twice, so we only need to track the state at each turn.
+	for {
+		wall, found := FindNearestWall(pos, c.walls)
+		if !found {
+			return false // Walked off the map
+		}
 
-				// Rotate 90
-				pos.value = 'v'
-				pos.X = wall.X - 1
-			} else if pos.value == 'v' { // South
-				for i := pos.Y; i < wall.Y; i++ {
-					loc := Coordinate{X: pos.X, Y: i, value: '<'}
-					w, f := FindNearestWall(loc, walls)
-					if f && slices.Contains(hitWalls, DirectionToInt(loc)|w.X<<8|w.Y<<16) {
-						loc.Y++ // Place wall in "front" of current location
-						log.Printf("Loop at [x=%d, y=%d]", loc.X, loc.Y)
-						loops = append(loops, Coordinate{X: loc.X, Y: loc.Y, value: 'O'})
-					}
-
-					coord := pos.X | i<<16
-					if !slices.Contains(seen, coord) {
-						seen = append(seen, coord)
-					}
-				}
+		if !c.states.Add(wall.X+wall.Y*c.width, DirectionToInt(pos)-1) {
+			return true
+		}
+		MoveToWall(&pos, wall)
+	}
+}
 
-				// Rotate 90
-				pos.value = '<'
-				pos.Y = wall.Y - 1
-			} else if pos.value == '<' { // West
-				for i := wall.X + 1; i < pos.X; i++ {
-					loc := Coordinate{X: i, Y: pos.Y, value: '^'}
-					w, f := FindNearestWall(loc, walls)
-					if f && slices.Contains(hitWalls, DirectionToInt(loc)|w.X<<8|w.Y<<16) {
-						loc.X-- // Place wall in "front" of current location
-						log.Printf("Loop at [x=%d, y=%d]", loc.X, loc.Y)
-						loops = append(loops, Coordinate{X: loc.X, Y: loc.Y, value: 'O'})
-					}
-
-					coord := i | pos.Y<<16
-					if !slices.Contains(seen, coord) {
-						seen = append(seen, coord)
-					}
-				}
+// Finds the positions where placing an obstruction would trap the guard in a
+// loop, checking candidates across the given number of workers.
+func FindObstructions(pos Coordinate, walls []Coordinate, size []int, workers int) ([]Coordinate, error) {
+	// The guard can only run into an obstruction that is on their original
+	// path, so those are the only tiles worth checking. The starting tile is
+	// skipped since the guard is already standing there.
+	path, err := WalkPath(pos, NewWallIndex(walls), size)
+	if err != nil {
+		return nil, err
+	}
+	candidates := []Coordinate{}
+	start := pos.X + pos.Y*size[0]
+	for i := range path.All() {
+		if i != start {
+			candidates = append(candidates, Coordinate{X: i % size[0], Y: i / size[0], value: 'O'})
+		}
+	}
 
-				// Rotate 90
-				pos.value = '^'
-				pos.X = wall.X + 1
-			}
-		} else {
-			if pos.value == '^' { // North
-				for i := 0; i < pos.Y; i++ {
-					loc := Coordinate{X: pos.X, Y: i, value: '>'}
-					w, f := FindNearestWall(loc, walls)
-					if f && slices.Contains(hitWalls, DirectionToInt(loc)|w.X<<8|w.Y<<16) {
-						loc.Y-- // Place wall in "front" of current location
-						log.Printf("Loop at [x=%d, y=%d]", loc.X, loc.Y)
-						loops = append(loops, Coordinate{X: loc.X, Y: loc.Y, value: 'O'})
-					}
-
-					coord := pos.X | i<<16
-					if !slices.Contains(seen, coord) {
-						seen = append(seen, coord)
-					}
-				}
-				pos.Y = 0 // Move to edge
-			} else if pos.value == '>' { // East
-				for i := pos.X; i < size[0]; i++ {
-					loc := Coordinate{X: i, Y: pos.Y, value: 'v'}
-					w, f := FindNearestWall(loc, walls)
-					if f && slices.Contains(hitWalls, DirectionToInt(loc)|w.X<<8|w.Y<<16) {
-						loc.X++ // Place wall in "front" of current location
-						log.Printf("Loop at [x=%d, y=%d]", loc.X, loc.Y)
-						loops = append(loops, Coordinate{X: loc.X, Y: loc.Y, value: 'O'})
-					}
-
-					coord := i | pos.Y<<16
-					if !slices.Contains(seen, coord) {
-						seen = append(seen, coord)
-					}
+	// Every simulation is independent, so workers just grab the next
+	// candidate until there are none left. Results are stored by index so the
+	// order doesn't depend on which worker finished first.
+	looped := make([]bool, len(candidates))
+	next := atomic.Int64{}
+	wg := sync.WaitGroup{}
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checker := NewLoopChecker(walls, size)
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(candidates) {
+					return
 				}
-				pos.X = size[0] - 1 // Move to edge
-			} else if pos.value == 'v' { // South
-				for i := pos.Y; i < size[1]; i++ {
-					loc := Coordinate{X: pos.X, Y: i, value: '<'}
-					w, f := FindNearestWall(loc, walls)
-					if f && slices.Contains(hitWalls, DirectionToInt(loc)|w.X<<8|w.Y<<16) {
-						loc.Y++ // Place wall in "front" of current location
-						log.Printf("Loop at [x=%d, y=%d]", loc.X, loc.Y)
-						loops = append(loops, Coordinate{X: loc.X, Y: loc.Y, value: 'O'})
-					}
-
-					coord := pos.X | i<<16
-					if !slices.Contains(seen, coord) {
-						seen = append(seen, coord)
-					}
-				}
-				pos.Y = size[1] - 1 // Move to edge
-			} else if pos.value == '<' { // West
-				for i := 0; i < pos.X; i++ {
-					loc := Coordinate{X: i, Y: pos.Y, value: '^'}
-					w, f := FindNearestWall(loc, walls)
-					if f && slices.Contains(hitWalls, DirectionToInt(loc)|w.X<<8|w.Y<<16) {
-						loc.X-- // Place wall in "front" of current location
-						log.Printf("Loop at [x=%d, y=%d]", loc.X, loc.Y)
-						loops = append(loops, Coordinate{X: loc.X, Y: loc.Y, value: 'O'})
-					}
-
-					coord := i | pos.Y<<16
-					if !slices.Contains(seen, coord) {
-						seen = append(seen, coord)
-					}
-				}
-				pos.X = 0 // Move to edge
+				looped[i] = checker.IsLoop(pos, candidates[i])
 			}
-			break
+		}()
+	}
+	wg.Wait()
+
+	loops := []Coordinate{}
+	for i, obstruction := range candidates {
+		if looped[i] {
+			loops = append(loops, obstruction)
+		}
+	}
+	return loops, nil
+}
+
+func Part1(pos Coordinate, walls []Coordinate, size []int) (int, error) {
+	path, err := WalkPath(pos, NewWallIndex(walls), size)
+	if err != nil {
+		return 0, err
+	}
+	return path.Len(), nil
+}
+
+func Part2(pos Coordinate, walls []Coordinate, size []int) (int, error) {
+	loops, err := FindObstructions(pos, walls, size, *workers)
+	if err != nil {
+		return 0, err
+	}
+	if *explain {
+		for _, obstruction := range loops {
+			log.Print(Explain(pos, walls, size, obstruction))
 		}
+		fmt.Println(ObstructionMap(pos, walls, size, loops))
 	}
-	PrintGraph(pos, walls, size, loops)
-	return len(loops)
+	return len(loops), nil
 }
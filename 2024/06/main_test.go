@@ -0,0 +1,270 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/grid"
+)
+
+// Generates a random map with roughly the given fraction of walls, and a
+// guard facing north that is able to walk off the map.
+func randomMap(rng *rand.Rand, width int, height int, density float64) (Coordinate, []Coordinate, []int) {
+	size := []int{width, height}
+	for {
+		walls := []Coordinate{}
+		for y := range height {
+			for x := range width {
+				if rng.Float64() < density {
+					walls = append(walls, Coordinate{X: x, Y: y, value: '#'})
+				}
+			}
+		}
+
+		pos := Coordinate{X: rng.Intn(width), Y: rng.Intn(height), value: '^'}
+		if slices.Contains(walls, Coordinate{X: pos.X, Y: pos.Y, value: '#'}) {
+			continue
+		}
+
+		// Obstructions only make sense if the guard leaves the map without one
+		if _, err := WalkPath(pos, NewWallIndex(walls), size); err == nil {
+			return pos, walls, size
+		}
+	}
+}
+
+// Parses a map from a string, failing the test if it isn't valid
+func parseMap(t *testing.T, input string) (Coordinate, []Coordinate, []int) {
+	t.Helper()
+	guards, walls, size, err := GetInputData(strings.NewReader(input), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return guards[0], walls, size
+}
+
+const example = `....#.....
+.........#
+..........
+..#.......
+.......#..
+..........
+.#..^.....
+........#.
+#.........
+......#...`
+
+func TestExample(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		part1 int
+		part2 int
+	}{
+		{"example", example, 41, 6},
+		// The same map turned on its side, with the guard turned along with it
+		{"rotated", strings.Join(rotateRight(strings.Split(example, "\n")), "\n"), 41, 6},
+		{"no walls", "...\n.^.\n...", 2, 0},
+	}
+	for _, tt := range tests {
+		pos, walls, size := parseMap(t, tt.input)
+		if got, err := Part1(pos, walls, size); err != nil || got != tt.part1 {
+			t.Errorf("%s: Part1 = %d, %v, want %d", tt.name, got, err, tt.part1)
+		}
+		if got, err := Part2(pos, walls, size); err != nil || got != tt.part2 {
+			t.Errorf("%s: Part2 = %d, %v, want %d", tt.name, got, err, tt.part2)
+		}
+	}
+}
+
+// Rotates a map 90 degrees clockwise, turning the guard along with it
+func rotateRight(lines []string) []string {
+	turn := map[byte]byte{'^': '>', '>': 'v', 'v': '<', '<': '^'}
+	rotated := make([]string, len(lines[0]))
+	for x := range rotated {
+		row := make([]byte, len(lines))
+		for y := range lines {
+			c := lines[len(lines)-1-y][x]
+			if next, ok := turn[c]; ok {
+				c = next
+			}
+			row[y] = c
+		}
+		rotated[x] = string(row)
+	}
+	return rotated
+}
+
+func TestPatrolLoops(t *testing.T) {
+	// The guard walks around the middle without ever reaching the edge
+	pos, walls, size := parseMap(t, ".#...\n....#\n.^...\n#....\n...#.")
+	if _, err := Part1(pos, walls, size); !errors.Is(err, ErrNoExit) {
+		t.Errorf("Part1 error = %v, want %v", err, ErrNoExit)
+	}
+	if _, err := FindObstructions(pos, walls, size, 2); !errors.Is(err, ErrNoExit) {
+		t.Errorf("FindObstructions error = %v, want %v", err, ErrNoExit)
+	}
+}
+
+// Tries an obstruction on every empty tile with the step by step simulation
+func bruteForceObstructions(pos Coordinate, walls []Coordinate, size []int) []Coordinate {
+	loops := []Coordinate{}
+	m := BuildMap(walls, size)
+	for p, c := range m.All() {
+		if c != '.' || (p == grid.Point{X: pos.X, Y: pos.Y}) {
+			continue
+		}
+		m.Set(p, 'O')
+		if NewSimulator(m, pos).Run() == Looped {
+			loops = append(loops, Coordinate{X: p.X, Y: p.Y, value: 'O'})
+		}
+		m.Set(p, '.')
+	}
+	return loops
+}
+
+func TestFindObstructionsBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(31))
+	total := 0
+	for range 30 {
+		pos, walls, size := randomMap(rng, 5+rng.Intn(20), 5+rng.Intn(20), 0.15)
+		got, err := FindObstructions(pos, walls, size, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := bruteForceObstructions(pos, walls, size)
+		total += len(want)
+		if !slices.Equal(got, want) {
+			t.Errorf("FindObstructions found %v, want %v\n%s", got, want, ObstructionMap(pos, walls, size, want))
+		}
+	}
+	if total == 0 {
+		t.Fatal("none of the maps had any obstructions to check")
+	}
+}
+
+func TestFindObstructionsWorkers(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	total := 0
+	for range 50 {
+		pos, walls, size := randomMap(rng, 5+rng.Intn(30), 5+rng.Intn(30), 0.1)
+		want, err := FindObstructions(pos, walls, size, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += len(want)
+
+		// Double check against the (much slower) step by step simulation
+		for _, obstruction := range want {
+			m := BuildMap(walls, size)
+			m.Set(grid.Point{X: obstruction.X, Y: obstruction.Y}, 'O')
+			if NewSimulator(m, pos).Run() != Looped {
+				t.Errorf("obstruction at [x=%d, y=%d] does not cause a loop", obstruction.X, obstruction.Y)
+			}
+		}
+
+		for _, workers := range []int{0, 2, 3, 8, runtime.NumCPU()} {
+			if got, _ := FindObstructions(pos, walls, size, workers); !slices.Equal(got, want) {
+				t.Errorf("workers=%d found %v, want %v", workers, got, want)
+			}
+		}
+	}
+	if total == 0 {
+		t.Fatal("none of the maps had any obstructions to check")
+	}
+}
+
+// Builds an n x n map where the guard spirals outwards from the middle, with
+// a gap of 2 tiles between each lap, until they walk off the map. This gives
+// a path about as long as the real input's, which random maps never do.
+func spiralMap(n int) (Coordinate, []Coordinate, []int) {
+	start := grid.Point{X: n / 2, Y: n / 2}
+	walls := []Coordinate{}
+
+	pos, heading := start, grid.North
+	for length := 2; ; length += 2 {
+		for range 2 {
+			pos = pos.Add(heading.Delta().Scale(length))
+			wall := pos.Add(heading.Delta())
+			if wall.X < 0 || wall.X >= n || wall.Y < 0 || wall.Y >= n {
+				return Coordinate{X: start.X, Y: start.Y, value: '^'}, walls, []int{n, n}
+			}
+			walls = append(walls, Coordinate{X: wall.X, Y: wall.Y, value: '#'})
+			heading = heading.Right()
+		}
+	}
+}
+
+func BenchmarkFindObstructions(b *testing.B) {
+	pos, walls, size := spiralMap(130)
+	for _, workers := range slices.Compact([]int{1, runtime.NumCPU()}) {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for range b.N {
+				FindObstructions(pos, walls, size, workers)
+			}
+		})
+	}
+}
+
+func TestObstructionMapNonSquare(t *testing.T) {
+	rng := rand.New(rand.NewSource(36))
+	total := 0
+	for range 20 {
+		pos, walls, size := randomMap(rng, 20+rng.Intn(10), 5+rng.Intn(10), 0.1)
+		loops, _ := FindObstructions(pos, walls, size, 1)
+		total += len(loops)
+
+		rows := strings.Split(strings.TrimSuffix(ObstructionMap(pos, walls, size, loops), "\n"), "\n")
+		if len(rows) != size[1] || len(rows[0]) != size[0] {
+			t.Fatalf("map is %dx%d, want %dx%d", len(rows[0]), len(rows), size[0], size[1])
+		}
+		if rows[pos.Y][pos.X] != byte(pos.value) {
+			t.Errorf("guard isn't at [x=%d, y=%d]", pos.X, pos.Y)
+		}
+		for _, wall := range walls {
+			if rows[wall.Y][wall.X] != '#' {
+				t.Errorf("wall at [x=%d, y=%d] is missing", wall.X, wall.Y)
+			}
+		}
+		for _, obstruction := range loops {
+			if rows[obstruction.Y][obstruction.X] != 'O' {
+				t.Errorf("obstruction at [x=%d, y=%d] is missing", obstruction.X, obstruction.Y)
+			}
+		}
+	}
+	if total == 0 {
+		t.Fatal("none of the maps had any obstructions to draw")
+	}
+}
+
+func TestIsLoopOnExistingWall(t *testing.T) {
+	rng := rand.New(rand.NewSource(33))
+	pos, walls, size := randomMap(rng, 20, 20, 0.1)
+	want, _ := FindObstructions(pos, walls, size, 1)
+
+	// Checking an obstruction on top of every wall must not remove any of them
+	checker := NewLoopChecker(walls, size)
+	for _, wall := range walls {
+		if checker.IsLoop(pos, Coordinate{X: wall.X, Y: wall.Y, value: 'O'}) {
+			t.Errorf("obstruction on the existing wall at [x=%d, y=%d] caused a loop", wall.X, wall.Y)
+		}
+	}
+	if checker.walls.Len() != len(walls) {
+		t.Fatalf("checker has %d walls left, want %d", checker.walls.Len(), len(walls))
+	}
+
+	got := []Coordinate{}
+	for _, candidate := range want {
+		if checker.IsLoop(pos, candidate) {
+			got = append(got, candidate)
+		}
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("reused checker found %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,109 @@
+// Package bitset has compact sets of small non-negative integers, which are a
+// lot faster than maps or slices for tracking visited cells on a grid.
+package bitset
+
+import (
+	"iter"
+	"math/bits"
+)
+
+// BitSet is a fixed size set of the integers 0 to size-1, using 1 bit each.
+type BitSet struct {
+	words []uint64
+	size  int
+}
+
+// New creates an empty BitSet that can hold the integers 0 to size-1.
+func New(size int) *BitSet {
+	return &BitSet{words: make([]uint64, (size+63)/64), size: size}
+}
+
+// Size returns the number of integers the set can hold.
+func (b *BitSet) Size() int {
+	return b.size
+}
+
+// Add inserts i into the set and reports whether it was newly added.
+func (b *BitSet) Add(i int) bool {
+	word, mask := i/64, uint64(1)<<(i%64)
+	if b.words[word]&mask != 0 {
+		return false
+	}
+	b.words[word] |= mask
+	return true
+}
+
+// Remove deletes i from the set.
+func (b *BitSet) Remove(i int) {
+	b.words[i/64] &^= uint64(1) << (i % 64)
+}
+
+// Has reports whether i is in the set.
+func (b *BitSet) Has(i int) bool {
+	return b.words[i/64]&(uint64(1)<<(i%64)) != 0
+}
+
+// Len returns the number of integers in the set.
+func (b *BitSet) Len() int {
+	count := 0
+	for _, w := range b.words {
+		count += bits.OnesCount64(w)
+	}
+	return count
+}
+
+// Clear removes everything from the set while keeping its memory, so it can
+// be reused between simulations without allocating.
+func (b *BitSet) Clear() {
+	clear(b.words)
+}
+
+// All iterates over every integer in the set in ascending order.
+func (b *BitSet) All() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i, w := range b.words {
+			for w != 0 {
+				// Pop the lowest set bit off each time
+				bit := bits.TrailingZeros64(w)
+				if !yield(i*64 + bit) {
+					return
+				}
+				w &= w - 1
+			}
+		}
+	}
+}
+
+// StateSet tracks up to 4 states (such as a heading) for each cell, using 4
+// bits per cell. It is useful for detecting loops where revisiting a cell is
+// fine, but revisiting it in the same state is not.
+type StateSet struct {
+	bits BitSet
+}
+
+// NewStateSet creates an empty StateSet for the cells 0 to cells-1.
+func NewStateSet(cells int) *StateSet {
+	return &StateSet{bits: *New(cells * 4)}
+}
+
+// Add marks cell as visited in state (0-3) and reports whether it was newly
+// added.
+func (s *StateSet) Add(cell int, state int) bool {
+	return s.bits.Add(cell*4 + state)
+}
+
+// Has reports whether cell has been visited in state (0-3).
+func (s *StateSet) Has(cell int, state int) bool {
+	return s.bits.Has(cell*4 + state)
+}
+
+// HasCell reports whether cell has been visited in any state.
+func (s *StateSet) HasCell(cell int) bool {
+	i := cell * 4
+	return (s.bits.words[i/64]>>(i%64))&0xf != 0
+}
+
+// Clear removes everything from the set while keeping its memory.
+func (s *StateSet) Clear() {
+	s.bits.Clear()
+}
@@ -0,0 +1,130 @@
+// Package collection has generic Counter (multiset) and Set types, so the
+// frequency and membership bookkeeping that shows up in most puzzles doesn't
+// have to be rebuilt out of maps every time.
+package collection
+
+import (
+	"cmp"
+	"iter"
+	"maps"
+	"slices"
+)
+
+// Counter counts how many times each item has been seen, like a multiset.
+// Items with a count of 0 are not stored. The zero value is an empty Counter
+// that is ready to use.
+type Counter[K comparable] struct {
+	counts map[K]int
+	total  int
+}
+
+// Entry is a single item in a Counter and how many times it was seen.
+type Entry[K comparable] struct {
+	Item  K
+	Count int
+}
+
+// NewCounter creates a Counter that has seen each of the items once.
+func NewCounter[K comparable](items ...K) *Counter[K] {
+	c := &Counter[K]{counts: make(map[K]int)}
+	for _, item := range items {
+		c.Add(item)
+	}
+	return c
+}
+
+// Add increments the count of each item by 1.
+func (c *Counter[K]) Add(items ...K) {
+	for _, item := range items {
+		c.AddN(item, 1)
+	}
+}
+
+// AddN increments the count of item by n, which may be negative. Counts never
+// go below 0.
+func (c *Counter[K]) AddN(item K, n int) {
+	old := c.counts[item]
+	count := max(old+n, 0)
+	if count == 0 {
+		delete(c.counts, item)
+	} else {
+		if c.counts == nil {
+			c.counts = make(map[K]int)
+		}
+		c.counts[item] = count
+	}
+	c.total += count - old
+}
+
+// Count returns how many times item has been seen.
+func (c *Counter[K]) Count(item K) int {
+	return c.counts[item]
+}
+
+// Len returns the number of distinct items.
+func (c *Counter[K]) Len() int {
+	return len(c.counts)
+}
+
+// Total returns the sum of all the counts.
+func (c *Counter[K]) Total() int {
+	return c.total
+}
+
+// All iterates over every item and its count, in no particular order.
+func (c *Counter[K]) All() iter.Seq2[K, int] {
+	return maps.All(c.counts)
+}
+
+// MostCommon returns the n items with the highest counts, highest first.
+// If n is negative every item is returned. Ties are in no particular order.
+func (c *Counter[K]) MostCommon(n int) []Entry[K] {
+	entries := make([]Entry[K], 0, len(c.counts))
+	for item, count := range c.counts {
+		entries = append(entries, Entry[K]{Item: item, Count: count})
+	}
+	slices.SortFunc(entries, func(a, b Entry[K]) int {
+		return cmp.Compare(b.Count, a.Count)
+	})
+	if n >= 0 && n < len(entries) {
+		entries = entries[:n]
+	}
+	return entries
+}
+
+// Clone returns a copy of the Counter.
+func (c *Counter[K]) Clone() *Counter[K] {
+	return &Counter[K]{counts: maps.Clone(c.counts), total: c.total}
+}
+
+// Intersect returns a new Counter with the items in both, using the smaller
+// of the two counts.
+func (c *Counter[K]) Intersect(other *Counter[K]) *Counter[K] {
+	result := NewCounter[K]()
+	for item, count := range c.counts {
+		result.AddN(item, min(count, other.Count(item)))
+	}
+	return result
+}
+
+// Union returns a new Counter with the items in either, using the larger of
+// the two counts.
+func (c *Counter[K]) Union(other *Counter[K]) *Counter[K] {
+	result := c.Clone()
+	for item, count := range other.counts {
+		if count > result.Count(item) {
+			result.AddN(item, count-result.Count(item))
+		}
+	}
+	return result
+}
+
+// Subtract returns a new Counter with the counts of other taken away, dropping
+// any items that reach 0.
+func (c *Counter[K]) Subtract(other *Counter[K]) *Counter[K] {
+	result := c.Clone()
+	for item, count := range other.counts {
+		result.AddN(item, -count)
+	}
+	return result
+}
@@ -0,0 +1,107 @@
+package collection
+
+import (
+	"maps"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// Checks a Counter against the counts it should have, including the total
+func checkCounts(t *testing.T, name string, c *Counter[int], want map[int]int) {
+	t.Helper()
+	total := 0
+	for _, count := range want {
+		total += count
+	}
+	if got := maps.Collect(c.All()); !maps.Equal(got, want) || c.Len() != len(want) || c.Total() != total {
+		t.Errorf("%s = %v (len %d, total %d), want %v (len %d, total %d)", name, got, c.Len(), c.Total(), want, len(want), total)
+	}
+}
+
+func TestCounterZeroValue(t *testing.T) {
+	var c Counter[string]
+	if c.Count("a") != 0 || c.Len() != 0 || c.Total() != 0 {
+		t.Error("zero value Counter isn't empty")
+	}
+	c.AddN("a", -1) // Must not panic either
+	c.Add("a", "b", "a")
+	if c.Count("a") != 2 || c.Count("b") != 1 || c.Total() != 3 {
+		t.Errorf("zero value Counter after Add = %v", c.MostCommon(-1))
+	}
+}
+
+func TestCounterClamp(t *testing.T) {
+	c := NewCounter(1, 1, 2)
+	c.AddN(1, -5) // Only 2 of them to take away
+	checkCounts(t, "after AddN(1, -5)", c, map[int]int{2: 1})
+	c.AddN(3, -1)
+	checkCounts(t, "after AddN(3, -1)", c, map[int]int{2: 1})
+	c.AddN(2, 4)
+	checkCounts(t, "after AddN(2, 4)", c, map[int]int{2: 5})
+}
+
+func TestMostCommon(t *testing.T) {
+	c := NewCounter('a', 'b', 'b', 'c', 'c', 'c', 'd', 'd', 'd', 'd')
+	all := []Entry[rune]{{'d', 4}, {'c', 3}, {'b', 2}, {'a', 1}}
+	tests := []struct {
+		n    int
+		want []Entry[rune]
+	}{
+		{-1, all},
+		{0, []Entry[rune]{}},
+		{2, all[:2]},
+		{4, all},
+		{10, all},
+	}
+	for _, tt := range tests {
+		if got := c.MostCommon(tt.n); !slices.Equal(got, tt.want) {
+			t.Errorf("MostCommon(%d) = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+	if got := NewCounter[rune]().MostCommon(3); len(got) != 0 {
+		t.Errorf("MostCommon of an empty Counter = %v", got)
+	}
+}
+
+// Builds a random Counter and the counts it should have
+func randomCounter(rng *rand.Rand) (*Counter[int], map[int]int) {
+	c, counts := NewCounter[int](), map[int]int{}
+	for range rng.Intn(20) {
+		item, n := rng.Intn(8), rng.Intn(6)-2
+		c.AddN(item, n)
+		if counts[item] = max(counts[item]+n, 0); counts[item] == 0 {
+			delete(counts, item)
+		}
+	}
+	return c, counts
+}
+
+func TestCounterSetOperations(t *testing.T) {
+	rng := rand.New(rand.NewSource(46))
+	for range 500 {
+		a, countsA := randomCounter(rng)
+		b, countsB := randomCounter(rng)
+		checkCounts(t, "random Counter", a, countsA)
+
+		intersect, union, subtract := map[int]int{}, maps.Clone(countsA), map[int]int{}
+		for item, count := range countsA {
+			if n := min(count, countsB[item]); n > 0 {
+				intersect[item] = n
+			}
+			if n := count - countsB[item]; n > 0 {
+				subtract[item] = n
+			}
+		}
+		for item, count := range countsB {
+			union[item] = max(union[item], count)
+		}
+		checkCounts(t, "Intersect", a.Intersect(b), intersect)
+		checkCounts(t, "Union", a.Union(b), union)
+		checkCounts(t, "Subtract", a.Subtract(b), subtract)
+
+		// None of them should have changed the originals
+		checkCounts(t, "a", a, countsA)
+		checkCounts(t, "b", b, countsB)
+	}
+}
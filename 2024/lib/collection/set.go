@@ -0,0 +1,92 @@
+package collection
+
+import (
+	"iter"
+	"maps"
+)
+
+// Set is an unordered collection of unique items. The zero value is an empty
+// set that is ready to use.
+type Set[T comparable] struct {
+	items map[T]struct{}
+}
+
+// NewSet creates a Set containing the items.
+func NewSet[T comparable](items ...T) *Set[T] {
+	s := &Set[T]{items: make(map[T]struct{}, len(items))}
+	s.Add(items...)
+	return s
+}
+
+// Add inserts each of the items into the set.
+func (s *Set[T]) Add(items ...T) {
+	if s.items == nil {
+		s.items = make(map[T]struct{}, len(items))
+	}
+	for _, item := range items {
+		s.items[item] = struct{}{}
+	}
+}
+
+// Remove deletes each of the items from the set.
+func (s *Set[T]) Remove(items ...T) {
+	for _, item := range items {
+		delete(s.items, item)
+	}
+}
+
+// Has reports whether item is in the set.
+func (s *Set[T]) Has(item T) bool {
+	_, ok := s.items[item]
+	return ok
+}
+
+// Len returns the number of items in the set.
+func (s *Set[T]) Len() int {
+	return len(s.items)
+}
+
+// All iterates over every item in the set, in no particular order.
+func (s *Set[T]) All() iter.Seq[T] {
+	return maps.Keys(s.items)
+}
+
+// Clone returns a copy of the set.
+func (s *Set[T]) Clone() *Set[T] {
+	return &Set[T]{items: maps.Clone(s.items)}
+}
+
+// Union returns a new set with the items in either set.
+func (s *Set[T]) Union(other *Set[T]) *Set[T] {
+	result := s.Clone()
+	for item := range other.items {
+		result.Add(item)
+	}
+	return result
+}
+
+// Intersect returns a new set with the items in both sets.
+func (s *Set[T]) Intersect(other *Set[T]) *Set[T] {
+	// Loop over whichever set is smaller
+	if other.Len() < s.Len() {
+		s, other = other, s
+	}
+	result := NewSet[T]()
+	for item := range s.items {
+		if other.Has(item) {
+			result.Add(item)
+		}
+	}
+	return result
+}
+
+// Difference returns a new set with the items that are not in other.
+func (s *Set[T]) Difference(other *Set[T]) *Set[T] {
+	result := NewSet[T]()
+	for item := range s.items {
+		if !other.Has(item) {
+			result.Add(item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package collection
+
+import (
+	"maps"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// Checks a Set holds exactly the items it should
+func checkSet(t *testing.T, name string, s *Set[int], want map[int]bool) {
+	t.Helper()
+	got := slices.Sorted(s.All())
+	if !slices.Equal(got, slices.Sorted(maps.Keys(want))) || s.Len() != len(want) {
+		t.Errorf("%s = %v, want %v", name, got, slices.Sorted(maps.Keys(want)))
+	}
+}
+
+func TestSetZeroValue(t *testing.T) {
+	var s Set[string]
+	if s.Has("a") || s.Len() != 0 {
+		t.Error("zero value Set isn't empty")
+	}
+	s.Remove("a") // Must not panic either
+	s.Add("a", "b", "a")
+	if !s.Has("a") || !s.Has("b") || s.Len() != 2 {
+		t.Errorf("zero value Set after Add = %v", slices.Sorted(s.All()))
+	}
+
+	// Operations on a zero value Set work as well
+	var empty Set[string]
+	if got := empty.Union(&s); got.Len() != 2 {
+		t.Errorf("empty Union = %v", slices.Sorted(got.All()))
+	}
+	if got := empty.Clone(); got.Len() != 0 {
+		t.Errorf("empty Clone = %v", slices.Sorted(got.All()))
+	}
+	clone := empty.Clone()
+	clone.Add("c")
+	if empty.Has("c") {
+		t.Error("adding to a Clone changed the original")
+	}
+}
+
+// Builds a random Set and the items it should have
+func randomSet(rng *rand.Rand) (*Set[int], map[int]bool) {
+	s, items := NewSet[int](), map[int]bool{}
+	for range rng.Intn(20) {
+		item := rng.Intn(12)
+		if rng.Intn(4) == 0 {
+			s.Remove(item)
+			delete(items, item)
+		} else {
+			s.Add(item)
+			items[item] = true
+		}
+	}
+	return s, items
+}
+
+func TestSetOperations(t *testing.T) {
+	rng := rand.New(rand.NewSource(46))
+	for range 500 {
+		a, itemsA := randomSet(rng)
+		b, itemsB := randomSet(rng)
+		checkSet(t, "random Set", a, itemsA)
+
+		union, intersect, difference := maps.Clone(itemsA), map[int]bool{}, map[int]bool{}
+		maps.Copy(union, itemsB)
+		for item := range itemsA {
+			if itemsB[item] {
+				intersect[item] = true
+			} else {
+				difference[item] = true
+			}
+		}
+		checkSet(t, "Union", a.Union(b), union)
+		checkSet(t, "Intersect", a.Intersect(b), intersect)
+		checkSet(t, "Intersect (swapped)", b.Intersect(a), intersect)
+		checkSet(t, "Difference", a.Difference(b), difference)
+		checkSet(t, "a", a, itemsA)
+		checkSet(t, "b", b, itemsB)
+	}
+}
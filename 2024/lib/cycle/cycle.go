@@ -0,0 +1,107 @@
+// Package cycle detects repeating states in iterated functions, such as a
+// simulation that eventually loops back on itself.
+//
+// Every function here describes a cycle with two numbers: mu, the index of the
+// first state that is part of the cycle, and lambda, the length of the cycle.
+// So for every i >= mu, state i is the same as state i+lambda.
+package cycle
+
+// Floyd finds the cycle in start, next(start), next(next(start)), ... using
+// Floyd's tortoise and hare algorithm. It only uses constant memory, but next
+// must eventually repeat or this will never return.
+func Floyd[T comparable](start T, next func(T) T) (int, int) {
+	// Find a point inside the cycle by moving the hare twice as fast
+	tortoise, hare := next(start), next(next(start))
+	for tortoise != hare {
+		tortoise, hare = next(tortoise), next(next(hare))
+	}
+
+	// The distance from the start to the beginning of the cycle is the same
+	// as from the meeting point to the beginning of the cycle.
+	mu := 0
+	tortoise = start
+	for tortoise != hare {
+		tortoise, hare = next(tortoise), next(hare)
+		mu++
+	}
+
+	lambda := 1
+	hare = next(tortoise)
+	for tortoise != hare {
+		hare = next(hare)
+		lambda++
+	}
+	return mu, lambda
+}
+
+// Brent finds the same cycle as Floyd, but usually with fewer calls to next
+// by teleporting the tortoise ahead in powers of two.
+func Brent[T comparable](start T, next func(T) T) (int, int) {
+	power, lambda := 1, 1
+	tortoise, hare := start, next(start)
+	for tortoise != hare {
+		if power == lambda {
+			tortoise = hare
+			power *= 2
+			lambda = 0
+		}
+		hare = next(hare)
+		lambda++
+	}
+
+	// Move the hare lambda steps ahead, then walk both until they meet at the
+	// start of the cycle.
+	tortoise, hare = start, start
+	for range lambda {
+		hare = next(hare)
+	}
+	mu := 0
+	for tortoise != hare {
+		tortoise, hare = next(tortoise), next(hare)
+		mu++
+	}
+	return mu, lambda
+}
+
+// FindRepeat steps through the states until one repeats, returning the cycle
+// and true. States are compared by key, so they don't need to be comparable
+// themselves. If next returns false first (such as a simulation finishing)
+// then there is no cycle, and it returns false.
+//
+// This uses memory for every state seen, but only calls next once per state.
+func FindRepeat[T any, K comparable](start T, next func(T) (T, bool), key func(T) K) (int, int, bool) {
+	seen := map[K]int{key(start): 0}
+	state := start
+	for i := 1; ; i++ {
+		var ok bool
+		state, ok = next(state)
+		if !ok {
+			return 0, 0, false
+		}
+
+		k := key(state)
+		if first, ok := seen[k]; ok {
+			return first, i - first, true
+		}
+		seen[k] = i
+	}
+}
+
+// Skip returns the state after calling next n times, which can be billions of
+// times, by finding the cycle and jumping over all the repeats of it.
+func Skip[T any, K comparable](start T, n int, next func(T) T, key func(T) K) T {
+	history := []T{start}
+	seen := map[K]int{key(start): 0}
+	state := start
+	for i := 1; i <= n; i++ {
+		state = next(state)
+		k := key(state)
+		if mu, ok := seen[k]; ok {
+			lambda := i - mu
+			return history[mu+(n-mu)%lambda]
+		}
+		seen[k] = i
+		history = append(history, state)
+	}
+	return state // Finished before anything repeated
+}
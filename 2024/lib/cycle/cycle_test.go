@@ -0,0 +1,98 @@
+package cycle
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Builds a random function on 0 to n-1, which always ends up in a cycle
+func randomFunction(seed int64, n int) func(int) int {
+	rng := rand.New(rand.NewSource(seed))
+	to := make([]int, n)
+	for i := range to {
+		to[i] = rng.Intn(n)
+	}
+	return func(x int) int { return to[x] }
+}
+
+// Finds the cycle the slow and obvious way, remembering every state
+func oracle(start int, next func(int) int) (int, int) {
+	seen := map[int]int{}
+	state := start
+	for i := 0; ; i++ {
+		if first, ok := seen[state]; ok {
+			return first, i - first
+		}
+		seen[state] = i
+		state = next(state)
+	}
+}
+
+func FuzzCycle(f *testing.F) {
+	f.Add(int64(0), uint8(1), uint8(0), uint16(0))
+	f.Add(int64(1), uint8(2), uint8(1), uint16(1))
+	f.Add(int64(38), uint8(64), uint8(7), uint16(1000))
+	f.Add(int64(-5), uint8(255), uint8(200), uint16(65535))
+	f.Fuzz(func(t *testing.T, seed int64, size uint8, first uint8, steps uint16) {
+		n := 1 + int(size)
+		start := int(first) % n
+		next := randomFunction(seed, n)
+		mu, lambda := oracle(start, next)
+
+		if gotMu, gotLambda := Floyd(start, next); gotMu != mu || gotLambda != lambda {
+			t.Errorf("Floyd = %d, %d, want %d, %d", gotMu, gotLambda, mu, lambda)
+		}
+		if gotMu, gotLambda := Brent(start, next); gotMu != mu || gotLambda != lambda {
+			t.Errorf("Brent = %d, %d, want %d, %d", gotMu, gotLambda, mu, lambda)
+		}
+
+		identity := func(x int) int { return x }
+		forever := func(x int) (int, bool) { return next(x), true }
+		if gotMu, gotLambda, ok := FindRepeat(start, forever, identity); !ok || gotMu != mu || gotLambda != lambda {
+			t.Errorf("FindRepeat = %d, %d, %t, want %d, %d", gotMu, gotLambda, ok, mu, lambda)
+		}
+
+		// Stopping just before the first repeat means there's no cycle yet
+		calls := 0
+		limited := func(x int) (int, bool) {
+			calls++
+			return next(x), calls < mu+lambda
+		}
+		if _, _, ok := FindRepeat(start, limited, identity); ok {
+			t.Errorf("FindRepeat found a cycle after %d states, before anything repeated", calls)
+		}
+
+		// Skip must land on the same state as stepping one at a time
+		want := start
+		for range steps {
+			want = next(want)
+		}
+		if got := Skip(start, int(steps), next, identity); got != want {
+			t.Errorf("Skip(%d) = %d, want %d", steps, got, want)
+		}
+
+		// And for far more steps than could ever be walked
+		far := 1_000_000_000_000 + int(steps)
+		want = start
+		for range mu + (far-mu)%lambda {
+			want = next(want)
+		}
+		if got := Skip(start, far, next, identity); got != want {
+			t.Errorf("Skip(%d) = %d, want %d", far, got, want)
+		}
+	})
+}
+
+// States that aren't comparable can still be tracked by key
+func TestFindRepeatByKey(t *testing.T) {
+	next := func(s []int) ([]int, bool) { return []int{(s[0]*3 + 1) % 10}, true }
+	key := func(s []int) int { return s[0] }
+	// 2 -> 7 -> 2, so the cycle starts straight away
+	if mu, lambda, ok := FindRepeat([]int{2}, next, key); !ok || mu != 0 || lambda != 2 {
+		t.Errorf("FindRepeat = %d, %d, %t, want 0, 2, true", mu, lambda, ok)
+	}
+	// 0 -> 1 -> 4 -> 3 -> 0
+	if got := Skip([]int{0}, 1_000_000_001, func(s []int) []int { s, _ = next(s); return s }, key); got[0] != 1 {
+		t.Errorf("Skip = %v, want [1]", got)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/IAreKyleW00t/advent-of-code/2024/lib
+
+go 1.23.3
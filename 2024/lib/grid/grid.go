@@ -0,0 +1,186 @@
+// Package grid contains the 2D grid and point types shared by the puzzles
+// that work on character maps.
+package grid
+
+import (
+	"iter"
+	"strings"
+	"unicode/utf8"
+)
+
+// Grid is a dense, fixed size 2D grid of cells stored in row-major order.
+//
+// A grid may also be a window into a larger grid (see View), in which case
+// rows are stride cells apart in the shared backing slice.
+type Grid[T any] struct {
+	width  int
+	height int
+	stride int
+	offset int
+	cells  []T
+}
+
+// New creates a width x height grid with every cell set to the zero value.
+func New[T any](width int, height int) *Grid[T] {
+	return &Grid[T]{width: width, height: height, stride: width, cells: make([]T, width*height)}
+}
+
+// FromLines creates a byte grid from the lines of a puzzle input.
+// Every line is expected to be the same length as the first.
+func FromLines(lines []string) *Grid[byte] {
+	height := len(lines)
+	width := 0
+	if height > 0 {
+		width = len(lines[0])
+	}
+
+	g := New[byte](width, height)
+	for y, line := range lines {
+		copy(g.cells[y*width:(y+1)*width], line)
+	}
+	return g
+}
+
+// FromRunes creates a rune grid from the lines of a puzzle input, for inputs
+// that have multi-byte (non-ASCII) characters. Every line is expected to have
+// the same number of characters as the first.
+func FromRunes(lines []string) *Grid[rune] {
+	height := len(lines)
+	width := 0
+	if height > 0 {
+		width = utf8.RuneCountInString(lines[0])
+	}
+
+	g := New[rune](width, height)
+	for y, line := range lines {
+		copy(g.cells[y*width:(y+1)*width], []rune(line))
+	}
+	return g
+}
+
+// Width returns the number of columns in the grid.
+func (g *Grid[T]) Width() int {
+	return g.width
+}
+
+// Height returns the number of rows in the grid.
+func (g *Grid[T]) Height() int {
+	return g.height
+}
+
+// In reports whether p is within the bounds of the grid.
+func (g *Grid[T]) In(p Point) bool {
+	return p.X >= 0 && p.X < g.width && p.Y >= 0 && p.Y < g.height
+}
+
+// Position of p in the backing slice
+func (g *Grid[T]) index(p Point) int {
+	return g.offset + p.Y*g.stride + p.X
+}
+
+// Get returns the value at p, which must be in bounds.
+func (g *Grid[T]) Get(p Point) T {
+	return g.cells[g.index(p)]
+}
+
+// Lookup returns the value at p, and false if p is out of bounds.
+func (g *Grid[T]) Lookup(p Point) (T, bool) {
+	if !g.In(p) {
+		var zero T
+		return zero, false
+	}
+	return g.Get(p), true
+}
+
+// Set updates the value at p, which must be in bounds.
+func (g *Grid[T]) Set(p Point, value T) {
+	g.cells[g.index(p)] = value
+}
+
+// Row returns the cells of row y. The slice shares memory with the grid, so
+// changes to it will update the grid.
+func (g *Grid[T]) Row(y int) []T {
+	start := g.offset + y*g.stride
+	return g.cells[start : start+g.width : start+g.width]
+}
+
+// Fill sets every cell in the grid to value.
+func (g *Grid[T]) Fill(value T) {
+	for y := range g.height {
+		row := g.Row(y)
+		for x := range row {
+			row[x] = value
+		}
+	}
+}
+
+// Clone returns a deep copy of the grid. Cloning a view creates a new grid
+// that no longer shares memory with its parent.
+func (g *Grid[T]) Clone() *Grid[T] {
+	c := New[T](g.width, g.height)
+	for y := range g.height {
+		copy(c.Row(y), g.Row(y))
+	}
+	return c
+}
+
+// All iterates over every point and value in row-major order.
+func (g *Grid[T]) All() iter.Seq2[Point, T] {
+	return func(yield func(Point, T) bool) {
+		for y := range g.height {
+			for x := range g.width {
+				p := Point{X: x, Y: y}
+				if !yield(p, g.cells[g.index(p)]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Find returns the first point (in row-major order) whose value matches.
+func (g *Grid[T]) Find(match func(T) bool) (Point, bool) {
+	for p, v := range g.All() {
+		if match(v) {
+			return p, true
+		}
+	}
+	return Point{}, false
+}
+
+// Neighbors iterates over the in-bounds points around p using the given
+// offsets, such as Orthogonal or Surrounding.
+func (g *Grid[T]) Neighbors(p Point, offsets []Point) iter.Seq[Point] {
+	return func(yield func(Point) bool) {
+		for _, o := range offsets {
+			n := p.Add(o)
+			if g.In(n) && !yield(n) {
+				return
+			}
+		}
+	}
+}
+
+// Map creates a new grid of the same size by converting every cell.
+func Map[T any, U any](g *Grid[T], convert func(Point, T) U) *Grid[U] {
+	m := New[U](g.width, g.height)
+	for p, v := range g.All() {
+		m.Set(p, convert(p, v))
+	}
+	return m
+}
+
+// Render draws the grid as text, one line per row, using char to convert
+// each cell into a character.
+func (g *Grid[T]) Render(char func(Point, T) rune) string {
+	var sb strings.Builder
+	sb.Grow((g.width + 1) * g.height)
+	for y := range g.height {
+		for x := range g.width {
+			p := Point{X: x, Y: y}
+			sb.WriteRune(char(p, g.Get(p)))
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
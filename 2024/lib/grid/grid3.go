@@ -0,0 +1,239 @@
+package grid
+
+import "iter"
+
+// Point3 is a single X,Y,Z position in a 3D grid.
+type Point3 struct {
+	X int
+	Y int
+	Z int
+}
+
+// Add returns the sum of p and q.
+func (p Point3) Add(q Point3) Point3 {
+	return Point3{X: p.X + q.X, Y: p.Y + q.Y, Z: p.Z + q.Z}
+}
+
+// Sub returns the difference of p and q.
+func (p Point3) Sub(q Point3) Point3 {
+	return Point3{X: p.X - q.X, Y: p.Y - q.Y, Z: p.Z - q.Z}
+}
+
+// Scale returns p multiplied by n.
+func (p Point3) Scale(n int) Point3 {
+	return Point3{X: p.X * n, Y: p.Y * n, Z: p.Z * n}
+}
+
+// Offsets for the 6 face and 26 surrounding neighbors of a 3D point.
+var (
+	Orthogonal3  = []Point3{{0, 0, -1}, {0, -1, 0}, {-1, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	Surrounding3 = func() []Point3 {
+		offsets := []Point3{}
+		for _, o := range NeighborsN(3, true) {
+			offsets = append(offsets, Point3{X: o[0], Y: o[1], Z: o[2]})
+		}
+		return offsets
+	}()
+)
+
+// Grid3 is a dense, fixed size 3D grid of cells. It works the same way as
+// Grid, with an extra Z axis for layers.
+type Grid3[T any] struct {
+	width  int
+	height int
+	depth  int
+	cells  []T
+}
+
+// New3 creates a width x height x depth grid with every cell set to the zero
+// value.
+func New3[T any](width int, height int, depth int) *Grid3[T] {
+	return &Grid3[T]{width: width, height: height, depth: depth, cells: make([]T, width*height*depth)}
+}
+
+// Width returns the size of the X axis.
+func (g *Grid3[T]) Width() int {
+	return g.width
+}
+
+// Height returns the size of the Y axis.
+func (g *Grid3[T]) Height() int {
+	return g.height
+}
+
+// Depth returns the size of the Z axis.
+func (g *Grid3[T]) Depth() int {
+	return g.depth
+}
+
+// In reports whether p is within the bounds of the grid.
+func (g *Grid3[T]) In(p Point3) bool {
+	return p.X >= 0 && p.X < g.width && p.Y >= 0 && p.Y < g.height && p.Z >= 0 && p.Z < g.depth
+}
+
+// Position of p in the backing slice
+func (g *Grid3[T]) index(p Point3) int {
+	return (p.Z*g.height+p.Y)*g.width + p.X
+}
+
+// Get returns the value at p, which must be in bounds.
+func (g *Grid3[T]) Get(p Point3) T {
+	return g.cells[g.index(p)]
+}
+
+// Lookup returns the value at p, and false if p is out of bounds.
+func (g *Grid3[T]) Lookup(p Point3) (T, bool) {
+	if !g.In(p) {
+		var zero T
+		return zero, false
+	}
+	return g.Get(p), true
+}
+
+// Set updates the value at p, which must be in bounds.
+func (g *Grid3[T]) Set(p Point3, value T) {
+	g.cells[g.index(p)] = value
+}
+
+// Fill sets every cell in the grid to value.
+func (g *Grid3[T]) Fill(value T) {
+	for i := range g.cells {
+		g.cells[i] = value
+	}
+}
+
+// Clone returns a deep copy of the grid.
+func (g *Grid3[T]) Clone() *Grid3[T] {
+	c := New3[T](g.width, g.height, g.depth)
+	copy(c.cells, g.cells)
+	return c
+}
+
+// Layer returns a copy of the 2D slice of the grid at height z.
+func (g *Grid3[T]) Layer(z int) *Grid[T] {
+	layer := New[T](g.width, g.height)
+	copy(layer.cells, g.cells[z*g.width*g.height:(z+1)*g.width*g.height])
+	return layer
+}
+
+// All iterates over every point and value, layer by layer in row-major order.
+func (g *Grid3[T]) All() iter.Seq2[Point3, T] {
+	return func(yield func(Point3, T) bool) {
+		i := 0
+		for z := range g.depth {
+			for y := range g.height {
+				for x := range g.width {
+					if !yield(Point3{X: x, Y: y, Z: z}, g.cells[i]) {
+						return
+					}
+					i++
+				}
+			}
+		}
+	}
+}
+
+// Find returns the first point whose value matches.
+func (g *Grid3[T]) Find(match func(T) bool) (Point3, bool) {
+	for p, v := range g.All() {
+		if match(v) {
+			return p, true
+		}
+	}
+	return Point3{}, false
+}
+
+// Neighbors iterates over the in-bounds points around p using the given
+// offsets, such as Orthogonal3 or Surrounding3.
+func (g *Grid3[T]) Neighbors(p Point3, offsets []Point3) iter.Seq[Point3] {
+	return func(yield func(Point3) bool) {
+		for _, o := range offsets {
+			n := p.Add(o)
+			if g.In(n) && !yield(n) {
+				return
+			}
+		}
+	}
+}
+
+// PointN is a position with any number of dimensions, for puzzles where the
+// number of axes isn't known ahead of time.
+type PointN []int
+
+// Add returns the sum of p and q, which must have the same dimensions.
+func (p PointN) Add(q PointN) PointN {
+	sum := make(PointN, len(p))
+	for i := range p {
+		sum[i] = p[i] + q[i]
+	}
+	return sum
+}
+
+// Sub returns the difference of p and q, which must have the same dimensions.
+func (p PointN) Sub(q PointN) PointN {
+	diff := make(PointN, len(p))
+	for i := range p {
+		diff[i] = p[i] - q[i]
+	}
+	return diff
+}
+
+// Scale returns p multiplied by n.
+func (p PointN) Scale(n int) PointN {
+	scaled := make(PointN, len(p))
+	for i := range p {
+		scaled[i] = p[i] * n
+	}
+	return scaled
+}
+
+// Equal reports whether p and q are the same point.
+func (p PointN) Equal(q PointN) bool {
+	if len(p) != len(q) {
+		return false
+	}
+	for i := range p {
+		if p[i] != q[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// NeighborsN returns the neighbor offsets for a point with the given number
+// of dimensions. Without diagonals there are 2*dims neighbors (only one axis
+// changes at a time), with diagonals there are 3^dims-1.
+func NeighborsN(dims int, diagonal bool) []PointN {
+	offsets := []PointN{}
+	if !diagonal {
+		for axis := range dims {
+			for _, d := range []int{-1, 1} {
+				o := make(PointN, dims)
+				o[axis] = d
+				offsets = append(offsets, o)
+			}
+		}
+		return offsets
+	}
+
+	// Count through every combination of -1/0/1 on each axis like a base 3
+	// number, skipping the all zero offset which is the point itself.
+	total := 1
+	for range dims {
+		total *= 3
+	}
+	for n := range total {
+		o := make(PointN, dims)
+		zero := true
+		for axis, rest := 0, n; axis < dims; axis, rest = axis+1, rest/3 {
+			o[axis] = rest%3 - 1
+			if o[axis] != 0 {
+				zero = false
+			}
+		}
+		if !zero {
+			offsets = append(offsets, o)
+		}
+	}
+	return offsets
+}
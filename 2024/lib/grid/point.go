@@ -0,0 +1,98 @@
+package grid
+
+// Point is a single X,Y position on a 2D grid.
+// X grows to the right (east) and Y grows downwards (south), which matches
+// the order that puzzle inputs are read in.
+type Point struct {
+	X int
+	Y int
+}
+
+// Add returns the sum of p and q.
+func (p Point) Add(q Point) Point {
+	return Point{X: p.X + q.X, Y: p.Y + q.Y}
+}
+
+// Sub returns the difference of p and q.
+func (p Point) Sub(q Point) Point {
+	return Point{X: p.X - q.X, Y: p.Y - q.Y}
+}
+
+// Scale returns p multiplied by n.
+func (p Point) Scale(n int) Point {
+	return Point{X: p.X * n, Y: p.Y * n}
+}
+
+// Direction is one of the 4 cardinal headings, in clockwise order.
+type Direction int
+
+const (
+	North Direction = iota
+	East
+	South
+	West
+)
+
+// Directions lists every cardinal direction in clockwise order.
+var Directions = []Direction{North, East, South, West}
+
+// Offset of a single step in each direction
+var deltas = [...]Point{
+	North: {X: 0, Y: -1},
+	East:  {X: 1, Y: 0},
+	South: {X: 0, Y: 1},
+	West:  {X: -1, Y: 0},
+}
+
+// Delta returns the offset for a single step in direction d.
+func (d Direction) Delta() Point {
+	return deltas[d]
+}
+
+// Right returns the direction after turning 90° clockwise.
+func (d Direction) Right() Direction {
+	return (d + 1) % 4
+}
+
+// Left returns the direction after turning 90° counter-clockwise.
+func (d Direction) Left() Direction {
+	return (d + 3) % 4
+}
+
+// Reverse returns the opposite direction.
+func (d Direction) Reverse() Direction {
+	return (d + 2) % 4
+}
+
+// Rune returns the arrow character for the direction, as used in the puzzle
+// inputs (^ > v <).
+func (d Direction) Rune() rune {
+	return [...]rune{'^', '>', 'v', '<'}[d]
+}
+
+// ParseDirection converts an arrow character (^ > v <) into a Direction.
+func ParseDirection(r rune) (Direction, bool) {
+	switch r {
+	case '^':
+		return North, true
+	case '>':
+		return East, true
+	case 'v':
+		return South, true
+	case '<':
+		return West, true
+	}
+	return North, false
+}
+
+// Offsets for the 4 orthogonal, 4 diagonal and 8 surrounding neighbors of a
+// point.
+var (
+	Orthogonal  = []Point{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+	Diagonal    = []Point{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}}
+	Surrounding = []Point{
+		{-1, -1}, {0, -1}, {1, -1},
+		{-1, 0}, {1, 0},
+		{-1, 1}, {0, 1}, {1, 1},
+	}
+)
@@ -0,0 +1,224 @@
+package grid
+
+import (
+	"iter"
+	"maps"
+	"slices"
+)
+
+// Pack crams a point into a single int64 so it can be used as a cheap map key.
+// Both coordinates must fit in an int32.
+func (p Point) Pack() int64 {
+	return int64(p.Y)<<32 | int64(uint32(p.X))
+}
+
+// Unpack reverses Pack.
+func Unpack(key int64) Point {
+	return Point{X: int(int32(key)), Y: int(key >> 32)}
+}
+
+// SparseGrid is an unbounded 2D grid that only stores the cells that have been
+// set, which is useful for huge or infinite maps with few interesting cells.
+//
+// Every occupied cell is also indexed by row and column so that finding the
+// nearest occupied cell in a direction is a binary search instead of a scan.
+type SparseGrid[T any] struct {
+	Default T // Value returned for cells that have not been set
+
+	cells map[int64]T
+	rows  map[int][]int // Y -> sorted X's
+	cols  map[int][]int // X -> sorted Y's
+
+	min   Point
+	max   Point
+	dirty bool // Bounds need to be recalculated after a delete
+}
+
+// NewSparse creates an empty sparse grid where unset cells have value def.
+func NewSparse[T any](def T) *SparseGrid[T] {
+	return &SparseGrid[T]{
+		Default: def,
+		cells:   make(map[int64]T),
+		rows:    make(map[int][]int),
+		cols:    make(map[int][]int),
+	}
+}
+
+// FromGrid creates a sparse grid from the cells of a dense grid that match
+// keep, with every other cell becoming def.
+func FromGrid[T any](g *Grid[T], def T, keep func(T) bool) *SparseGrid[T] {
+	s := NewSparse(def)
+	for p, v := range g.All() {
+		if keep(v) {
+			s.Set(p, v)
+		}
+	}
+	return s
+}
+
+// Len returns the number of occupied cells.
+func (s *SparseGrid[T]) Len() int {
+	return len(s.cells)
+}
+
+// Get returns the value at p, or the default value if it is not set.
+func (s *SparseGrid[T]) Get(p Point) T {
+	if v, ok := s.cells[p.Pack()]; ok {
+		return v
+	}
+	return s.Default
+}
+
+// Lookup returns the value at p, and whether it has been set.
+func (s *SparseGrid[T]) Lookup(p Point) (T, bool) {
+	v, ok := s.cells[p.Pack()]
+	return v, ok
+}
+
+// Has reports whether p has been set.
+func (s *SparseGrid[T]) Has(p Point) bool {
+	_, ok := s.cells[p.Pack()]
+	return ok
+}
+
+// Set stores value at p, growing the bounding box if needed.
+func (s *SparseGrid[T]) Set(p Point, value T) {
+	key := p.Pack()
+	if _, ok := s.cells[key]; !ok {
+		s.rows[p.Y] = insertSorted(s.rows[p.Y], p.X)
+		s.cols[p.X] = insertSorted(s.cols[p.X], p.Y)
+
+		if len(s.cells) == 0 {
+			s.min, s.max = p, p
+		} else if !s.dirty {
+			s.min = Point{X: min(s.min.X, p.X), Y: min(s.min.Y, p.Y)}
+			s.max = Point{X: max(s.max.X, p.X), Y: max(s.max.Y, p.Y)}
+		}
+	}
+	s.cells[key] = value
+}
+
+// Delete clears the value at p so it goes back to the default.
+func (s *SparseGrid[T]) Delete(p Point) {
+	key := p.Pack()
+	if _, ok := s.cells[key]; !ok {
+		return
+	}
+	delete(s.cells, key)
+	s.rows[p.Y] = removeSorted(s.rows[p.Y], p.X)
+	if len(s.rows[p.Y]) == 0 {
+		delete(s.rows, p.Y)
+	}
+	s.cols[p.X] = removeSorted(s.cols[p.X], p.Y)
+	if len(s.cols[p.X]) == 0 {
+		delete(s.cols, p.X)
+	}
+
+	// Only recalculate the bounds if we removed something on the edge
+	if p.X == s.min.X || p.X == s.max.X || p.Y == s.min.Y || p.Y == s.max.Y {
+		s.dirty = true
+	}
+}
+
+// Bounds returns the top-left and bottom-right corners of the smallest box
+// that contains every occupied cell, and false if the grid is empty.
+func (s *SparseGrid[T]) Bounds() (Point, Point, bool) {
+	if len(s.cells) == 0 {
+		return Point{}, Point{}, false
+	}
+	if s.dirty {
+		s.min = Point{X: slices.Min(slices.Collect(maps.Keys(s.cols))), Y: slices.Min(slices.Collect(maps.Keys(s.rows)))}
+		s.max = Point{X: slices.Max(slices.Collect(maps.Keys(s.cols))), Y: slices.Max(slices.Collect(maps.Keys(s.rows)))}
+		s.dirty = false
+	}
+	return s.min, s.max, true
+}
+
+// All iterates over every occupied cell in row-major order.
+func (s *SparseGrid[T]) All() iter.Seq2[Point, T] {
+	return func(yield func(Point, T) bool) {
+		for _, y := range slices.Sorted(maps.Keys(s.rows)) {
+			for _, x := range s.rows[y] {
+				p := Point{X: x, Y: y}
+				if !yield(p, s.cells[p.Pack()]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Nearest finds the closest occupied cell to p when travelling in direction
+// d, not including p itself.
+func (s *SparseGrid[T]) Nearest(p Point, d Direction) (Point, bool) {
+	switch d {
+	case North:
+		// Closest Y that is smaller than ours
+		ys := s.cols[p.X]
+		i, _ := slices.BinarySearch(ys, p.Y)
+		if i > 0 {
+			return Point{X: p.X, Y: ys[i-1]}, true
+		}
+	case South:
+		// Closest Y that is larger than ours
+		ys := s.cols[p.X]
+		i, found := slices.BinarySearch(ys, p.Y)
+		if found {
+			i++
+		}
+		if i < len(ys) {
+			return Point{X: p.X, Y: ys[i]}, true
+		}
+	case West:
+		xs := s.rows[p.Y]
+		i, _ := slices.BinarySearch(xs, p.X)
+		if i > 0 {
+			return Point{X: xs[i-1], Y: p.Y}, true
+		}
+	case East:
+		xs := s.rows[p.Y]
+		i, found := slices.BinarySearch(xs, p.X)
+		if found {
+			i++
+		}
+		if i < len(xs) {
+			return Point{X: xs[i], Y: p.Y}, true
+		}
+	}
+	return Point{}, false
+}
+
+// ToGrid converts the sparse grid into a dense grid that covers its bounding
+// box, along with the point in the sparse grid that (0,0) maps to.
+// Unset cells are filled with the default value.
+func (s *SparseGrid[T]) ToGrid() (*Grid[T], Point) {
+	lo, hi, ok := s.Bounds()
+	if !ok {
+		return New[T](0, 0), Point{}
+	}
+
+	g := New[T](hi.X-lo.X+1, hi.Y-lo.Y+1)
+	g.Fill(s.Default)
+	for k, v := range s.cells {
+		g.Set(Unpack(k).Sub(lo), v)
+	}
+	return g, lo
+}
+
+// Utility function to insert a number into a sorted list (if it's not already there)
+func insertSorted(list []int, n int) []int {
+	i, found := slices.BinarySearch(list, n)
+	if found {
+		return list
+	}
+	return slices.Insert(list, i, n)
+}
+
+// Utility function to remove a number from a sorted list
+func removeSorted(list []int, n int) []int {
+	i, found := slices.BinarySearch(list, n)
+	if !found {
+		return list
+	}
+	return slices.Delete(list, i, i+1)
+}
@@ -0,0 +1,193 @@
+package grid
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestPackUnpack(t *testing.T) {
+	values := []int{0, 1, -1, 2, -2, 1000, -1000, math.MaxInt32, math.MinInt32}
+	for _, x := range values {
+		for _, y := range values {
+			p := Point{X: x, Y: y}
+			if got := Unpack(p.Pack()); got != p {
+				t.Errorf("Unpack(%v.Pack()) = %v", p, got)
+			}
+		}
+	}
+
+	// Neighbouring points must never share a key
+	keys := map[int64]Point{}
+	for y := -3; y <= 3; y++ {
+		for x := -3; x <= 3; x++ {
+			p := Point{X: x, Y: y}
+			if q, ok := keys[p.Pack()]; ok {
+				t.Errorf("%v and %v have the same key", p, q)
+			}
+			keys[p.Pack()] = p
+		}
+	}
+}
+
+// Finds the nearest cell by checking every one of them
+func nearest(cells map[Point]byte, p Point, d Direction) (Point, bool) {
+	best, bestDist, found := Point{}, 0, false
+	for q := range cells {
+		diff := q.Sub(p)
+		var dist int
+		switch d {
+		case North:
+			dist = -diff.Y
+		case South:
+			dist = diff.Y
+		case West:
+			dist = -diff.X
+		case East:
+			dist = diff.X
+		}
+		inLine := (d == North || d == South) && diff.X == 0 || (d == East || d == West) && diff.Y == 0
+		if !inLine || dist <= 0 {
+			continue
+		}
+		if !found || dist < bestDist {
+			best, bestDist, found = q, dist, true
+		}
+	}
+	return best, found
+}
+
+func TestSparseMatchesMap(t *testing.T) {
+	rng := rand.New(rand.NewSource(28))
+	for range 100 {
+		s := NewSparse[byte]('.')
+		cells := map[Point]byte{}
+		for range 300 {
+			// Small area around the origin so cells get hit more than once
+			p := Point{X: rng.Intn(13) - 6, Y: rng.Intn(13) - 6}
+			if rng.Intn(3) == 0 {
+				s.Delete(p)
+				delete(cells, p)
+			} else {
+				v := byte('a' + rng.Intn(26))
+				s.Set(p, v)
+				cells[p] = v
+			}
+
+			if s.Len() != len(cells) {
+				t.Fatalf("Len() = %d, want %d", s.Len(), len(cells))
+			}
+			want, ok := cells[p]
+			if !ok {
+				want = '.'
+			}
+			if s.Has(p) != ok || s.Get(p) != want {
+				t.Fatalf("Get(%v) = %c, %t, want %c, %t", p, s.Get(p), s.Has(p), want, ok)
+			}
+
+			// Bounds have to shrink again after deleting cells on the edge
+			lo, hi, ok := s.Bounds()
+			if ok != (len(cells) > 0) {
+				t.Fatalf("Bounds() ok = %t with %d cells", ok, len(cells))
+			}
+			if ok {
+				wantLo, wantHi := Point{X: math.MaxInt, Y: math.MaxInt}, Point{X: math.MinInt, Y: math.MinInt}
+				for q := range cells {
+					wantLo = Point{X: min(wantLo.X, q.X), Y: min(wantLo.Y, q.Y)}
+					wantHi = Point{X: max(wantHi.X, q.X), Y: max(wantHi.Y, q.Y)}
+				}
+				if lo != wantLo || hi != wantHi {
+					t.Fatalf("Bounds() = %v, %v, want %v, %v", lo, hi, wantLo, wantHi)
+				}
+			}
+
+			// Check from just outside the area too, so both ends are covered
+			from := Point{X: rng.Intn(17) - 8, Y: rng.Intn(17) - 8}
+			for _, d := range Directions {
+				got, gotOK := s.Nearest(from, d)
+				want, wantOK := nearest(cells, from, d)
+				if got != want || gotOK != wantOK {
+					t.Fatalf("Nearest(%v, %d) = %v, %t, want %v, %t", from, d, got, gotOK, want, wantOK)
+				}
+			}
+		}
+
+		// All goes row by row, left to right
+		points := []Point{}
+		for p, v := range s.All() {
+			if cells[p] != v {
+				t.Errorf("All() gave %c at %v, want %c", v, p, cells[p])
+			}
+			points = append(points, p)
+		}
+		if len(points) != len(cells) || !slices.IsSortedFunc(points, func(a Point, b Point) int {
+			if a.Y != b.Y {
+				return a.Y - b.Y
+			}
+			return a.X - b.X
+		}) {
+			t.Errorf("All() = %v, which isn't every cell in row-major order", points)
+		}
+	}
+}
+
+func TestNearestEnds(t *testing.T) {
+	s := NewSparse[byte]('.')
+	for _, p := range []Point{{X: -2, Y: 0}, {X: 0, Y: 0}, {X: 3, Y: 0}, {X: 0, Y: -4}, {X: 0, Y: 5}} {
+		s.Set(p, '#')
+	}
+
+	tests := []struct {
+		from  Point
+		dir   Direction
+		want  Point
+		found bool
+	}{
+		{Point{X: 3, Y: 0}, East, Point{}, false},    // Last in the row
+		{Point{X: -2, Y: 0}, West, Point{}, false},   // First in the row
+		{Point{X: 3, Y: 0}, West, Point{X: 0}, true}, // Skips itself
+		{Point{X: -2, Y: 0}, East, Point{X: 0}, true},
+		{Point{X: 9, Y: 0}, West, Point{X: 3}, true}, // Past the end
+		{Point{X: -9, Y: 0}, East, Point{X: -2}, true},
+		{Point{X: 0, Y: -4}, North, Point{}, false}, // Top of the column
+		{Point{X: 0, Y: 5}, South, Point{}, false},  // Bottom of the column
+		{Point{X: 0, Y: 5}, North, Point{}, true},
+		{Point{X: 0, Y: -4}, South, Point{}, true},
+		{Point{X: 0, Y: 1}, North, Point{}, true}, // Between cells
+		{Point{X: 0, Y: 1}, South, Point{Y: 5}, true},
+		{Point{X: 1, Y: 1}, North, Point{}, false}, // Empty column
+	}
+	for _, tt := range tests {
+		got, found := s.Nearest(tt.from, tt.dir)
+		if got != tt.want || found != tt.found {
+			t.Errorf("Nearest(%v, %c) = %v, %t, want %v, %t", tt.from, tt.dir.Rune(), got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestSparseRoundTrip(t *testing.T) {
+	g := parse("..#./#.../...#")
+	s := FromGrid(g, '.', func(c byte) bool { return c == '#' })
+	if s.Len() != 3 {
+		t.Fatalf("FromGrid kept %d cells, want 3", s.Len())
+	}
+
+	dense, origin := s.ToGrid()
+	if got := draw(dense); got != "..#./#.../...#" || origin != (Point{}) {
+		t.Errorf("ToGrid() = %s at %v", got, origin)
+	}
+
+	// Negative coordinates shift the origin, and deleting the bottom right
+	// corner shrinks the box
+	s.Set(Point{X: -2, Y: -1}, '#')
+	s.Delete(Point{X: 3, Y: 2})
+	dense, origin = s.ToGrid()
+	if got := draw(dense); got != "#..../....#/..#.." || origin != (Point{X: -2, Y: -1}) {
+		t.Errorf("ToGrid() = %s at %v", got, origin)
+	}
+
+	if dense, _ := NewSparse[byte]('.').ToGrid(); dense.Width() != 0 || dense.Height() != 0 {
+		t.Errorf("ToGrid() of an empty grid is %dx%d", dense.Width(), dense.Height())
+	}
+}
@@ -0,0 +1,94 @@
+package grid
+
+// View returns a width x height window into the grid with its top-left corner
+// at origin. The view shares memory with the grid, so changes made through
+// either one are visible in both. The window must be within the grid.
+func (g *Grid[T]) View(origin Point, width int, height int) *Grid[T] {
+	if origin.X < 0 || origin.Y < 0 || width < 0 || height < 0 ||
+		origin.X+width > g.width || origin.Y+height > g.height {
+		panic("grid: view is out of bounds")
+	}
+	return &Grid[T]{
+		width:  width,
+		height: height,
+		stride: g.stride,
+		offset: g.index(origin),
+		cells:  g.cells,
+	}
+}
+
+// Builds a new grid where each point is read from the source grid at the
+// position returned by src
+func transform[T any](g *Grid[T], width int, height int, src func(Point) Point) *Grid[T] {
+	t := New[T](width, height)
+	for y := range height {
+		for x := range width {
+			p := Point{X: x, Y: y}
+			t.Set(p, g.Get(src(p)))
+		}
+	}
+	return t
+}
+
+// Transpose returns a copy of the grid mirrored along its main diagonal, so
+// rows become columns.
+func (g *Grid[T]) Transpose() *Grid[T] {
+	return transform(g, g.height, g.width, func(p Point) Point {
+		return Point{X: p.Y, Y: p.X}
+	})
+}
+
+// RotateRight returns a copy of the grid rotated 90° clockwise.
+func (g *Grid[T]) RotateRight() *Grid[T] {
+	return transform(g, g.height, g.width, func(p Point) Point {
+		return Point{X: p.Y, Y: g.height - 1 - p.X}
+	})
+}
+
+// RotateLeft returns a copy of the grid rotated 90° counter-clockwise.
+func (g *Grid[T]) RotateLeft() *Grid[T] {
+	return transform(g, g.height, g.width, func(p Point) Point {
+		return Point{X: g.width - 1 - p.Y, Y: p.X}
+	})
+}
+
+// Rotate180 returns a copy of the grid turned upside down.
+func (g *Grid[T]) Rotate180() *Grid[T] {
+	return transform(g, g.width, g.height, func(p Point) Point {
+		return Point{X: g.width - 1 - p.X, Y: g.height - 1 - p.Y}
+	})
+}
+
+// FlipHorizontal returns a copy of the grid mirrored left to right.
+func (g *Grid[T]) FlipHorizontal() *Grid[T] {
+	return transform(g, g.width, g.height, func(p Point) Point {
+		return Point{X: g.width - 1 - p.X, Y: p.Y}
+	})
+}
+
+// FlipVertical returns a copy of the grid mirrored top to bottom.
+func (g *Grid[T]) FlipVertical() *Grid[T] {
+	return transform(g, g.width, g.height, func(p Point) Point {
+		return Point{X: p.X, Y: g.height - 1 - p.Y}
+	})
+}
+
+// Tile returns a new grid made of the grid repeated across x times and down
+// y times.
+func (g *Grid[T]) Tile(x int, y int) *Grid[T] {
+	return transform(g, g.width*x, g.height*y, func(p Point) Point {
+		return Point{X: p.X % g.width, Y: p.Y % g.height}
+	})
+}
+
+// Orientations returns all 8 rotations and reflections of the grid, starting
+// with a copy of the grid itself. Symmetric grids will have duplicates.
+func (g *Grid[T]) Orientations() []*Grid[T] {
+	r90 := g.RotateRight()
+	r180 := g.Rotate180()
+	r270 := g.RotateLeft()
+	return []*Grid[T]{
+		g.Clone(), r90, r180, r270,
+		g.FlipHorizontal(), r90.FlipHorizontal(), r180.FlipHorizontal(), r270.FlipHorizontal(),
+	}
+}
@@ -0,0 +1,147 @@
+package grid
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Utility function to build a byte grid from rows separated by /
+func parse(rows string) *Grid[byte] {
+	return FromLines(strings.Split(rows, "/"))
+}
+
+// Utility function to draw a byte grid with rows separated by /
+func draw(g *Grid[byte]) string {
+	text := g.Render(func(_ Point, c byte) rune { return rune(c) })
+	return strings.ReplaceAll(strings.TrimSuffix(text, "\n"), "\n", "/")
+}
+
+func TestTransforms(t *testing.T) {
+	// 3 wide and 2 tall, so mixing up width and height shows up
+	g := parse("abc/def")
+	tests := []struct {
+		name string
+		got  *Grid[byte]
+		want string
+	}{
+		{"Transpose", g.Transpose(), "ad/be/cf"},
+		{"RotateRight", g.RotateRight(), "da/eb/fc"},
+		{"RotateLeft", g.RotateLeft(), "cf/be/ad"},
+		{"Rotate180", g.Rotate180(), "fed/cba"},
+		{"FlipHorizontal", g.FlipHorizontal(), "cba/fed"},
+		{"FlipVertical", g.FlipVertical(), "def/abc"},
+		{"Tile", g.Tile(2, 2), "abcabc/defdef/abcabc/defdef"},
+		{"RotateRight x4", g.RotateRight().RotateRight().RotateRight().RotateRight(), "abc/def"},
+		{"RotateLeft of RotateRight", g.RotateRight().RotateLeft(), "abc/def"},
+	}
+	for _, tt := range tests {
+		if got := draw(tt.got); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	// None of the transforms should have touched the original
+	if got := draw(g); got != "abc/def" {
+		t.Errorf("original grid changed to %s", got)
+	}
+}
+
+func TestOrientations(t *testing.T) {
+	g := parse("abc/def")
+	want := []string{
+		"abc/def", "da/eb/fc", "fed/cba", "cf/be/ad",
+		"cba/fed", "ad/be/cf", "def/abc", "fc/eb/da",
+	}
+	got := []string{}
+	for _, o := range g.Orientations() {
+		got = append(got, draw(o))
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Orientations() = %v, want %v", got, want)
+	}
+
+	// The first orientation is a copy, not the grid itself
+	g.Orientations()[0].Set(Point{X: 0, Y: 0}, 'z')
+	if g.Get(Point{X: 0, Y: 0}) != 'a' {
+		t.Error("changing the first orientation changed the original grid")
+	}
+
+	// A fully symmetric grid looks the same every way round
+	for _, o := range parse("aba/bab/aba").Orientations() {
+		if got := draw(o); got != "aba/bab/aba" {
+			t.Errorf("symmetric grid orientation = %s", got)
+		}
+	}
+}
+
+func TestView(t *testing.T) {
+	g := parse("abcde/fghij/klmno/pqrst")
+	outer := g.View(Point{X: 1, Y: 1}, 4, 3)
+	inner := outer.View(Point{X: 1, Y: 1}, 2, 2)
+
+	if got := draw(outer); got != "ghij/lmno/qrst" {
+		t.Errorf("outer view = %s", got)
+	}
+	if got := draw(inner); got != "mn/rs" {
+		t.Errorf("inner view = %s", got)
+	}
+	if inner.Width() != 2 || inner.Height() != 2 || inner.In(Point{X: 2, Y: 0}) {
+		t.Errorf("inner view has the wrong bounds")
+	}
+
+	// Writes through a nested view reach all the way up to the parent
+	inner.Set(Point{X: 0, Y: 0}, 'M')
+	if g.Get(Point{X: 2, Y: 2}) != 'M' || outer.Get(Point{X: 1, Y: 1}) != 'M' {
+		t.Errorf("write through nested view didn't reach the parent: %s", draw(g))
+	}
+
+	// Row only covers the view's columns, and shares memory with the parent
+	row := inner.Row(1)
+	if string(row) != "rs" {
+		t.Errorf("inner.Row(1) = %q, want %q", row, "rs")
+	}
+	row[1] = 'S'
+	if g.Get(Point{X: 3, Y: 3}) != 'S' {
+		t.Errorf("write through Row didn't reach the parent: %s", draw(g))
+	}
+
+	// Appending to a row must never spill into the next one
+	_ = append(outer.Row(0), '!')
+	if g.Get(Point{X: 0, Y: 2}) != 'k' {
+		t.Errorf("appending to a view's row overwrote the parent: %s", draw(g))
+	}
+
+	// Fill only touches the cells in the view
+	inner.Fill('.')
+	if got := draw(g); got != "abcde/fghij/kl..o/pq..t" {
+		t.Errorf("after Fill the parent is %s", got)
+	}
+
+	// Clone copies just the view, and no longer shares memory
+	clone := outer.Clone()
+	if got := draw(clone); got != "ghij/l..o/q..t" {
+		t.Errorf("clone of view = %s", got)
+	}
+	clone.Set(Point{X: 0, Y: 0}, '#')
+	if g.Get(Point{X: 1, Y: 1}) != 'g' {
+		t.Error("changing a clone of a view changed the parent")
+	}
+
+	// Transforms of a view only see the view
+	if got := draw(inner.RotateRight()); got != "../.." {
+		t.Errorf("rotated view = %s", got)
+	}
+	if got := draw(outer.Transpose()); got != "glq/h../i../jot" {
+		t.Errorf("transposed view = %s", got)
+	}
+}
+
+func TestViewOutOfBounds(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("View past the edge of the grid should panic")
+		}
+	}()
+	parse("abc/def").View(Point{X: 1, Y: 0}, 3, 1)
+}
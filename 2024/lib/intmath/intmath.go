@@ -0,0 +1,261 @@
+// Package intmath has integer-only math helpers, so we never have to round
+// trip through float64 (which loses precision above 2^53) just to call
+// something like math.Abs.
+package intmath
+
+import "math/bits"
+
+// Signed is any signed integer type.
+type Signed interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64
+}
+
+// Unsigned is any unsigned integer type.
+type Unsigned interface {
+	~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Integer is any integer type.
+type Integer interface {
+	Signed | Unsigned
+}
+
+// Abs returns the absolute value of n. Like math.Abs, the smallest value of a
+// type has no positive equivalent, so it is returned unchanged.
+func Abs[T Signed](n T) T {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Sign returns -1, 0 or 1 depending on whether n is negative, zero or positive.
+func Sign[T Signed](n T) T {
+	if n < 0 {
+		return -1
+	} else if n > 0 {
+		return 1
+	}
+	return 0
+}
+
+// Min returns the smallest number, and panics if there are none.
+func Min[T Integer](nums ...T) T {
+	m := nums[0]
+	for _, n := range nums[1:] {
+		m = min(m, n)
+	}
+	return m
+}
+
+// Max returns the largest number, and panics if there are none.
+func Max[T Integer](nums ...T) T {
+	m := nums[0]
+	for _, n := range nums[1:] {
+		m = max(m, n)
+	}
+	return m
+}
+
+// Sum adds up all the numbers.
+func Sum[T Integer](nums ...T) T {
+	var total T
+	for _, n := range nums {
+		total += n
+	}
+	return total
+}
+
+// GCD returns the greatest common divisor of a and b, which is always
+// positive (or 0 if both are 0).
+func GCD[T Integer](a T, b T) T {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// LCM returns the least common multiple of all the numbers.
+func LCM[T Integer](nums ...T) T {
+	l := nums[0]
+	for _, n := range nums[1:] {
+		if l == 0 || n == 0 {
+			return 0
+		}
+		l = l / GCD(l, n) * n
+	}
+	if l < 0 {
+		return -l
+	}
+	return l
+}
+
+// Mod returns a modulo m, which unlike % is never negative for m > 0.
+func Mod[T Integer](a T, m T) T {
+	r := a % m
+	if r < 0 {
+		r += m
+	}
+	return r
+}
+
+// MulMod returns (a * b) mod m without overflowing, even if a * b would.
+// m must be positive.
+func MulMod[T Integer](a T, b T, m T) T {
+	hi, lo := bits.Mul64(uint64(Mod(a, m)), uint64(Mod(b, m)))
+	return T(bits.Rem64(hi, lo, uint64(m)))
+}
+
+// ModPow returns (base ^ exp) mod m using exponentiation by squaring.
+// exp must not be negative, and m must be positive.
+func ModPow[T Integer](base T, exp T, m T) T {
+	result := Mod(1, m)
+	base = Mod(base, m)
+	for exp > 0 {
+		if exp&1 == 1 {
+			result = MulMod(result, base, m)
+		}
+		base = MulMod(base, base, m)
+		exp >>= 1
+	}
+	return result
+}
+
+// ExtGCD returns the greatest common divisor of a and b, along with x and y
+// such that a*x + b*y = gcd (Bézout's identity).
+func ExtGCD[T Signed](a T, b T) (T, T, T) {
+	oldR, r := a, b
+	oldX, x := T(1), T(0)
+	oldY, y := T(0), T(1)
+	for r != 0 {
+		q := oldR / r
+		oldR, r = r, oldR-q*r
+		oldX, x = x, oldX-q*x
+		oldY, y = y, oldY-q*y
+	}
+	if oldR < 0 {
+		return -oldR, -oldX, -oldY
+	}
+	return oldR, oldX, oldY
+}
+
+// ModInverse returns x such that (a * x) mod m is 1, and false if there isn't
+// one (when a and m are not coprime).
+func ModInverse[T Signed](a T, m T) (T, bool) {
+	g, x, _ := ExtGCD(Mod(a, m), m)
+	if g != 1 {
+		return 0, false
+	}
+	return Mod(x, m), true
+}
+
+// CRT solves the system x = remainders[i] (mod moduli[i]) using the Chinese
+// Remainder Theorem. It returns the smallest non-negative x and the modulus it
+// repeats at (the LCM of the moduli), or false if there is no solution.
+// The moduli don't need to be coprime.
+func CRT[T Signed](remainders []T, moduli []T) (T, T, bool) {
+	x, m := T(0), T(1)
+	for i := range remainders {
+		// Merge x (mod m) with r (mod n) by solving x + m*k = r (mod n)
+		r, n := Mod(remainders[i], moduli[i]), moduli[i]
+		g, p, _ := ExtGCD(m, n)
+		if (r-x)%g != 0 {
+			return 0, 0, false
+		}
+
+		lcm := m / g * n
+		k := MulMod((r-x)/g, p, n/g)
+		x = Mod(x+MulMod(m, k, lcm), lcm)
+		m = lcm
+	}
+	return x, m, true
+}
+
+// Sqrt returns the largest integer whose square is at most n.
+// n must not be negative.
+func Sqrt[T Integer](n T) T {
+	if n < 2 {
+		return n
+	}
+
+	// Newton's method, starting from a guess that is always too big so it
+	// only ever moves downwards.
+	x := T(1) << ((bits.Len64(uint64(n)) + 1) / 2)
+	for {
+		y := (x + n/x) / 2
+		if y >= x {
+			return x
+		}
+		x = y
+	}
+}
+
+// NumDigits returns how many base 10 digits are in n, ignoring the sign.
+func NumDigits[T Integer](n T) int {
+	count := 1
+	for n /= 10; n != 0; n /= 10 {
+		count++
+	}
+	return count
+}
+
+// Digits returns the base 10 digits of n from most to least significant,
+// ignoring the sign.
+func Digits[T Integer](n T) []int {
+	digits := make([]int, NumDigits(n))
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(n % 10)
+		if d < 0 {
+			d = -d
+		}
+		digits[i] = d
+		n /= 10
+	}
+	return digits
+}
+
+// FromDigits builds a number from its base 10 digits, most significant first.
+func FromDigits[T Integer](digits []int) T {
+	var n T
+	for _, d := range digits {
+		n = n*10 + T(d)
+	}
+	return n
+}
+
+// Concat joins the digits of a and b together, so Concat(12, 345) is 12345.
+// b must not be negative.
+func Concat[T Integer](a T, b T) T {
+	for range NumDigits(b) {
+		a *= 10
+	}
+	return a + b
+}
+
+// AddChecked returns a + b, and false if the result overflowed.
+func AddChecked[T Integer](a T, b T) (T, bool) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return sum, false
+	}
+	return sum, true
+}
+
+// MulChecked returns a * b, and false if the result overflowed.
+func MulChecked[T Integer](a T, b T) (T, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+
+	// Dividing the product back out in both directions catches every
+	// overflow, including -1 * the smallest signed value (which wraps back
+	// around to itself).
+	product := a * b
+	if product/b != a || product/a != b {
+		return product, false
+	}
+	return product, true
+}
@@ -0,0 +1,169 @@
+package intmath
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+// Values that tend to find overflow bugs
+var edges = []int64{0, 1, -1, 2, -2, 3, 10, 1 << 31, -(1 << 31), 1 << 32, math.MaxInt64, math.MinInt64, math.MaxInt64 - 1, math.MinInt64 + 1}
+
+func FuzzMulMod(f *testing.F) {
+	for _, a := range edges {
+		for _, b := range edges {
+			f.Add(a, b, int64(math.MaxInt64))
+			f.Add(a, b, int64(1_000_000_007))
+		}
+	}
+	f.Fuzz(func(t *testing.T, a int64, b int64, m int64) {
+		if m <= 0 {
+			t.Skip()
+		}
+		want := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+		want.Mod(want, big.NewInt(m))
+		if got := MulMod(a, b, m); got != want.Int64() {
+			t.Errorf("MulMod(%d, %d, %d) = %d, want %d", a, b, m, got, want)
+		}
+	})
+}
+
+func FuzzModPow(f *testing.F) {
+	for _, b := range edges {
+		f.Add(b, int64(0), int64(7))
+		f.Add(b, int64(math.MaxInt64), int64(math.MaxInt64))
+		f.Add(b, int64(65537), int64(1))
+	}
+	f.Fuzz(func(t *testing.T, base int64, exp int64, m int64) {
+		if exp < 0 || m <= 0 {
+			t.Skip()
+		}
+		bigM := big.NewInt(m)
+		want := new(big.Int).Mod(big.NewInt(base), bigM)
+		want.Exp(want, big.NewInt(exp), bigM)
+		if got := ModPow(base, exp, m); got != want.Int64() {
+			t.Errorf("ModPow(%d, %d, %d) = %d, want %d", base, exp, m, got, want)
+		}
+	})
+}
+
+func FuzzSqrt(f *testing.F) {
+	for _, n := range edges {
+		f.Add(n)
+	}
+	f.Add(int64(3037000499 * 3037000499))
+	f.Add(int64(3037000499*3037000499 - 1))
+	f.Fuzz(func(t *testing.T, n int64) {
+		if n < 0 {
+			t.Skip()
+		}
+		s := big.NewInt(Sqrt(n))
+		next := new(big.Int).Add(s, big.NewInt(1))
+		bigN := big.NewInt(n)
+		if new(big.Int).Mul(s, s).Cmp(bigN) > 0 || new(big.Int).Mul(next, next).Cmp(bigN) <= 0 {
+			t.Errorf("Sqrt(%d) = %d, want s² ≤ n < (s+1)²", n, s)
+		}
+	})
+}
+
+func FuzzAddChecked(f *testing.F) {
+	for _, a := range edges {
+		for _, b := range edges {
+			f.Add(a, b)
+		}
+	}
+	f.Fuzz(func(t *testing.T, a int64, b int64) {
+		want := new(big.Int).Add(big.NewInt(a), big.NewInt(b))
+		got, ok := AddChecked(a, b)
+		if ok != want.IsInt64() || (ok && got != want.Int64()) {
+			t.Errorf("AddChecked(%d, %d) = %d, %t, want %d", a, b, got, ok, want)
+		}
+	})
+}
+
+func FuzzMulChecked(f *testing.F) {
+	for _, a := range edges {
+		for _, b := range edges {
+			f.Add(a, b)
+		}
+	}
+	f.Fuzz(func(t *testing.T, a int64, b int64) {
+		want := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+		got, ok := MulChecked(a, b)
+		if ok != want.IsInt64() || (ok && got != want.Int64()) {
+			t.Errorf("MulChecked(%d, %d) = %d, %t, want %d", a, b, got, ok, want)
+		}
+	})
+}
+
+// Small types are cheap enough to check every combination
+func TestCheckedInt8(t *testing.T) {
+	for a := math.MinInt8; a <= math.MaxInt8; a++ {
+		for b := math.MinInt8; b <= math.MaxInt8; b++ {
+			sum, ok := AddChecked(int8(a), int8(b))
+			if ok != (a+b >= math.MinInt8 && a+b <= math.MaxInt8) || (ok && int(sum) != a+b) {
+				t.Errorf("AddChecked(%d, %d) = %d, %t", a, b, sum, ok)
+			}
+			product, ok := MulChecked(int8(a), int8(b))
+			if ok != (a*b >= math.MinInt8 && a*b <= math.MaxInt8) || (ok && int(product) != a*b) {
+				t.Errorf("MulChecked(%d, %d) = %d, %t", a, b, product, ok)
+			}
+		}
+	}
+}
+
+func FuzzModInverse(f *testing.F) {
+	f.Add(int64(3), int64(11))
+	f.Add(int64(2), int64(4))
+	f.Add(int64(-7), int64(1_000_000_007))
+	f.Add(int64(math.MaxInt64), int64(math.MaxInt64-1))
+	f.Fuzz(func(t *testing.T, a int64, m int64) {
+		if m <= 0 {
+			t.Skip()
+		}
+		bigA, bigM := big.NewInt(a), big.NewInt(m)
+		gcd := new(big.Int).GCD(nil, nil, new(big.Int).Mod(bigA, bigM), bigM)
+		x, ok := ModInverse(a, m)
+		if ok != (gcd.Int64() == 1) {
+			t.Fatalf("ModInverse(%d, %d) = %d, %t, but gcd is %d", a, m, x, ok, gcd)
+		}
+		if !ok {
+			return
+		}
+
+		// Round trip: a * x = 1 (mod m)
+		check := new(big.Int).Mul(bigA, big.NewInt(x))
+		check.Sub(check, big.NewInt(1)).Mod(check, bigM)
+		if x < 0 || x >= m || check.Sign() != 0 {
+			t.Errorf("ModInverse(%d, %d) = %d, which is not an inverse", a, m, x)
+		}
+	})
+}
+
+func FuzzCRT(f *testing.F) {
+	f.Add(int64(0), int64(3), int64(3), int64(4))
+	f.Add(int64(2), int64(6), int64(4), int64(8))
+	f.Add(int64(1), int64(4), int64(2), int64(6))
+	f.Add(int64(-5), int64(999_983), int64(12), int64(1_000_003))
+	f.Fuzz(func(t *testing.T, r1 int64, m1 int64, r2 int64, m2 int64) {
+		// Keep the moduli small enough that their LCM fits in an int64
+		if m1 <= 0 || m2 <= 0 || m1 > 1<<30 || m2 > 1<<30 {
+			t.Skip()
+		}
+		x, lcm, ok := CRT([]int64{r1, r2}, []int64{m1, m2})
+
+		// There is a solution when the remainders agree mod the GCD
+		g := GCD(m1, m2)
+		if want := Mod(r1, g) == Mod(r2, g); ok != want {
+			t.Fatalf("CRT(%d mod %d, %d mod %d) = %d, %d, %t, want %t", r1, m1, r2, m2, x, lcm, ok, want)
+		}
+		if !ok {
+			return
+		}
+
+		// Round trip: x must satisfy both congruences
+		if lcm != LCM(m1, m2) || x < 0 || x >= lcm || Mod(x, m1) != Mod(r1, m1) || Mod(x, m2) != Mod(r2, m2) {
+			t.Errorf("CRT(%d mod %d, %d mod %d) = %d, %d, which doesn't solve it", r1, m1, r2, m2, x, lcm)
+		}
+	})
+}
@@ -0,0 +1,242 @@
+// Package order handles puzzles where a set of "X must come before Y" rules
+// decide the order of items, and the rules may only describe part of it.
+package order
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Rules is a set of "before must come before after" pairs, stored as an
+// adjacency set so checking a pair is a single lookup.
+type Rules[T comparable] struct {
+	after map[T]map[T]bool
+}
+
+// NewRules creates an empty set of rules.
+func NewRules[T comparable]() *Rules[T] {
+	return &Rules[T]{after: map[T]map[T]bool{}}
+}
+
+// Add records that before must come before after.
+func (r *Rules[T]) Add(before T, after T) {
+	if r.after[before] == nil {
+		r.after[before] = map[T]bool{}
+	}
+	r.after[before][after] = true
+}
+
+// Before reports whether there is a rule that a must come before b.
+func (r *Rules[T]) Before(a T, b T) bool {
+	return r.after[a][b]
+}
+
+// IsOrdered reports whether items breaks none of the rules. Rules for items
+// that aren't in the list are ignored.
+func (r *Rules[T]) IsOrdered(items []T) bool {
+	for i := range items {
+		for j := i + 1; j < len(items); j++ {
+			if r.Before(items[j], items[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// CycleError is returned when the rules that apply to a list contradict each
+// other, so there is no order that satisfies all of them.
+type CycleError[T comparable] struct {
+	Cycle []T // Items in the cycle, with the first repeated at the end
+}
+
+func (e *CycleError[T]) Error() string {
+	items := make([]string, len(e.Cycle))
+	for i, item := range e.Cycle {
+		items[i] = fmt.Sprint(item)
+	}
+	return "ordering rules form a cycle: " + strings.Join(items, " -> ")
+}
+
+// Sort returns a copy of items ordered so that none of the rules are broken,
+// using Kahn's algorithm on just the rules between the items in the list.
+// Items that the rules don't decide between keep their original order as much
+// as possible, and repeated items are sorted like any other. If the rules
+// contain a cycle a *CycleError is returned.
+func (r *Rules[T]) Sort(items []T) ([]T, error) {
+	// Count how many items have to come before each one. Everything is kept by
+	// position, so an item that is in the list twice is placed twice.
+	in := make([]int, len(items))
+	for _, a := range items {
+		for j, b := range items {
+			if r.Before(a, b) {
+				in[j]++
+			}
+		}
+	}
+
+	sorted := make([]T, 0, len(items))
+	placed := make([]bool, len(items))
+	for len(sorted) < len(items) {
+		// Take the first item that has nothing left in front of it
+		next := -1
+		for i := range items {
+			if !placed[i] && in[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			return nil, &CycleError[T]{Cycle: r.findCycle(items, placed)}
+		}
+
+		placed[next] = true
+		sorted = append(sorted, items[next])
+		for j, b := range items {
+			if r.Before(items[next], b) {
+				in[j]--
+			}
+		}
+	}
+	return sorted, nil
+}
+
+// Finds a cycle between the items that Kahn's algorithm couldn't place.
+// Every one of them has at least one unplaced item in front of it, so walking
+// backwards through those will eventually revisit an item. Returns nil if
+// that isn't the case, rather than walking forever.
+func (r *Rules[T]) findCycle(items []T, placed []bool) []T {
+	start := slices.Index(placed, false)
+	if start < 0 {
+		return nil
+	}
+
+	path := []int{start}
+	index := map[int]int{start: 0}
+	for {
+		current := path[len(path)-1]
+		found := false
+		for prev := range items {
+			if placed[prev] || !r.Before(items[prev], items[current]) {
+				continue
+			}
+			if i, ok := index[prev]; ok {
+				// Found the loop, flip it around so it reads in rule order
+				cycle := make([]T, 0, len(path)-i+1)
+				for _, j := range slices.Backward(path[i:]) {
+					cycle = append(cycle, items[j])
+				}
+				return append(cycle, items[current])
+			}
+			index[prev] = len(path)
+			path = append(path, prev)
+			found = true
+			break
+		}
+		if !found {
+			return nil
+		}
+	}
+}
+
+// Compare can be used with slices.SortFunc to sort by the rules. It returns -1
+// if a must come before b, 1 if b must come before a, and 0 if there is no
+// rule between them.
+//
+// This is only safe when the rules are a strict total order on the items
+// being sorted (see ValidateTotalOrder), otherwise the result is undefined.
+func (r *Rules[T]) Compare(a T, b T) int {
+	if r.Before(a, b) {
+		return -1
+	} else if r.Before(b, a) {
+		return 1
+	}
+	return 0
+}
+
+// OrderError explains why the rules are not a strict total order on a list of
+// items, using the smallest set of items that shows the problem.
+type OrderError[T comparable] struct {
+	Property string `json:"property"` // Which property of a strict total order is broken
+	Items    []T    `json:"items"`
+}
+
+func (e *OrderError[T]) Error() string {
+	items := make([]string, len(e.Items))
+	for i, item := range e.Items {
+		items[i] = fmt.Sprint(item)
+	}
+	return fmt.Sprintf("rules are not a strict total order (%s): %s", e.Property, strings.Join(items, ", "))
+}
+
+// ValidateTotalOrder checks whether the rules between items form a strict
+// total order, which is what makes sorting with Compare safe. That means:
+//   - irreflexive: no item has to come before itself
+//   - antisymmetric: no pair has to come before each other
+//   - total: every pair has a rule between them
+//   - transitive: if a is before b and b is before c, then a is before c
+//
+// Problems are checked in that order, and the first pair (or triple) by
+// position in items is returned as an *OrderError.
+func (r *Rules[T]) ValidateTotalOrder(items []T) error {
+	for _, a := range items {
+		if r.Before(a, a) {
+			return &OrderError[T]{Property: "irreflexive", Items: []T{a}}
+		}
+	}
+	for i, a := range items {
+		for _, b := range items[i+1:] {
+			if r.Before(a, b) && r.Before(b, a) {
+				return &OrderError[T]{Property: "antisymmetric", Items: []T{a, b}}
+			}
+		}
+	}
+	for i, a := range items {
+		for _, b := range items[i+1:] {
+			if !r.Before(a, b) && !r.Before(b, a) {
+				return &OrderError[T]{Property: "total", Items: []T{a, b}}
+			}
+		}
+	}
+	for _, a := range items {
+		for _, b := range items {
+			if !r.Before(a, b) {
+				continue
+			}
+			for _, c := range items {
+				if r.Before(b, c) && !r.Before(a, c) {
+					return &OrderError[T]{Property: "transitive", Items: []T{a, b, c}}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Violation is a rule that a list breaks, and where the two items are in it.
+type Violation[T comparable] struct {
+	Before      T   `json:"before"`       // Item that should have come first
+	After       T   `json:"after"`        // Item that should have come second
+	BeforeIndex int `json:"before_index"` // Where Before actually is
+	AfterIndex  int `json:"after_index"`  // Where After actually is (in front of Before)
+}
+
+// Violations returns every rule that items breaks, ordered by the position of
+// the item that should have come first.
+func (r *Rules[T]) Violations(items []T) []Violation[T] {
+	violations := []Violation[T]{}
+	for j, before := range items {
+		for i, after := range items[:j] {
+			if r.Before(before, after) {
+				violations = append(violations, Violation[T]{
+					Before:      before,
+					After:       after,
+					BeforeIndex: j,
+					AfterIndex:  i,
+				})
+			}
+		}
+	}
+	return violations
+}
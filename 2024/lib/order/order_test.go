@@ -0,0 +1,80 @@
+package order
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestSortRepeatedItems(t *testing.T) {
+	rules := NewRules[int]()
+	rules.Add(1, 2)
+	rules.Add(3, 1)
+
+	tests := []struct {
+		items []int
+		want  []int
+	}{
+		{[]int{2, 1, 2}, []int{1, 2, 2}},
+		{[]int{2, 2, 2}, []int{2, 2, 2}},
+		{[]int{2, 1, 3, 1}, []int{3, 1, 1, 2}},
+		{[]int{4, 2, 4, 1}, []int{4, 4, 1, 2}},
+	}
+	for _, tt := range tests {
+		got, err := rules.Sort(tt.items)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("Sort(%v) = %v, %v, want %v", tt.items, got, err, tt.want)
+		}
+	}
+}
+
+func TestSortCycle(t *testing.T) {
+	rules := NewRules[int]()
+	rules.Add(1, 2)
+	rules.Add(2, 3)
+	rules.Add(3, 1)
+	rules.Add(5, 5)
+
+	tests := []struct {
+		items []int
+		want  []int
+	}{
+		{[]int{4, 1, 2, 3}, []int{2, 3, 1, 2}},
+		{[]int{3, 2, 1, 3}, []int{1, 2, 3, 1}},
+		{[]int{5, 1}, []int{5, 5}},
+	}
+	for _, tt := range tests {
+		_, err := rules.Sort(tt.items)
+		var cycle *CycleError[int]
+		if !errors.As(err, &cycle) || !slices.Equal(cycle.Cycle, tt.want) {
+			t.Errorf("Sort(%v) error = %v, want cycle %v", tt.items, err, tt.want)
+		}
+	}
+}
+
+func TestSortRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(40))
+	for range 500 {
+		// Rules only ever go from a smaller to a bigger item, so there's no cycle
+		rules := NewRules[int]()
+		for range rng.Intn(30) {
+			a, b := rng.Intn(10), rng.Intn(10)
+			if a < b {
+				rules.Add(a, b)
+			}
+		}
+		items := make([]int, rng.Intn(12))
+		for i := range items {
+			items[i] = rng.Intn(10)
+		}
+
+		got, err := rules.Sort(items)
+		if err != nil || !rules.IsOrdered(got) {
+			t.Fatalf("Sort(%v) = %v, %v, which isn't in order", items, got, err)
+		}
+		if !slices.Equal(slices.Sorted(slices.Values(got)), slices.Sorted(slices.Values(items))) {
+			t.Errorf("Sort(%v) = %v, which has different items", items, got)
+		}
+	}
+}
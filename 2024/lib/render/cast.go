@@ -0,0 +1,85 @@
+package render
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/grid"
+)
+
+// WriteCast encodes the recorded frames as an asciinema v2 recording, which
+// can be played back in a terminal with `asciinema play`.
+// Characters are colored using the Recorder's palette.
+func (r *Recorder) WriteCast(w io.Writer) error {
+	frames := r.Frames()
+	if len(frames) == 0 {
+		return errors.New("no frames recorded")
+	}
+
+	out := bufio.NewWriter(w)
+	header, err := json.Marshal(map[string]any{
+		"version": 2,
+		"width":   frames[0].Width(),
+		"height":  frames[0].Height(),
+		"env":     map[string]string{"TERM": "xterm-256color"},
+	})
+	if err != nil {
+		return err
+	}
+	out.Write(header)
+	out.WriteByte('\n')
+
+	// Each frame redraws the whole screen from the top-left corner, and only
+	// emits a color escape code when the color actually changes.
+	escapes := map[rune]string{}
+	for i, frame := range frames {
+		var sb strings.Builder
+		if i == 0 {
+			// Clear anything already on the screen before the first frame
+			sb.WriteString("\x1b[2J")
+		}
+		sb.WriteString("\x1b[H")
+		last := ""
+		for y := range frame.Height() {
+			for x := range frame.Width() {
+				c := frame.Get(grid.Point{X: x, Y: y})
+				esc, ok := escapes[c]
+				if !ok {
+					esc = ansiColor(r.Palette.Color(c))
+					escapes[c] = esc
+				}
+				if esc != last {
+					sb.WriteString(esc)
+					last = esc
+				}
+				sb.WriteRune(c)
+			}
+			sb.WriteString("\r\n")
+		}
+		sb.WriteString("\x1b[0m")
+
+		event, err := json.Marshal([]any{
+			(r.Delay * time.Duration(i)).Seconds(),
+			"o",
+			sb.String(),
+		})
+		if err != nil {
+			return err
+		}
+		out.Write(event)
+		out.WriteByte('\n')
+	}
+	return out.Flush()
+}
+
+// Converts a color into a 24-bit ANSI foreground escape code
+func ansiColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", r>>8, g>>8, b>>8)
+}
@@ -0,0 +1,58 @@
+package render
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"slices"
+)
+
+// WriteGIF encodes the recorded frames as a looping animated GIF.
+func (r *Recorder) WriteGIF(w io.Writer) error {
+	frames := r.Frames()
+	if len(frames) == 0 {
+		return errors.New("no frames recorded")
+	}
+
+	// GIFs are limited to 256 colors, so build an indexed palette from the
+	// background and every configured character color. Characters are sorted
+	// so the output is the same between runs.
+	chars := []rune{}
+	for c := range r.Palette.Colors {
+		chars = append(chars, c)
+	}
+	slices.Sort(chars)
+
+	palette := color.Palette{r.Palette.Background, r.Palette.Foreground}
+	index := make(map[rune]uint8, len(chars))
+	for _, c := range chars {
+		if len(palette) == 256 {
+			return errors.New("palette has more than 256 colors")
+		}
+		index[c] = uint8(len(palette))
+		palette = append(palette, r.Palette.Colors[c])
+	}
+
+	size := max(r.CellSize, 1)
+	delay := int(r.Delay.Milliseconds() / 10) // GIF delays are in 1/100s
+	anim := &gif.GIF{}
+	for _, frame := range frames {
+		img := image.NewPaletted(image.Rect(0, 0, frame.Width()*size, frame.Height()*size), palette)
+		for p, c := range frame.All() {
+			i, ok := index[c]
+			if !ok {
+				i = 1 // Foreground
+			}
+			for y := p.Y * size; y < (p.Y+1)*size; y++ {
+				for x := p.X * size; x < (p.X+1)*size; x++ {
+					img.SetColorIndex(x, y, i)
+				}
+			}
+		}
+		anim.Image = append(anim.Image, img)
+		anim.Delay = append(anim.Delay, delay)
+	}
+	return gif.EncodeAll(w, anim)
+}
@@ -0,0 +1,139 @@
+// Package render turns grids into something that can be looked at outside of
+// the solution itself, such as animations of a simulation.
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/grid"
+)
+
+// Palette maps grid characters to the colors used when drawing them.
+// Any character without a color is drawn using the Foreground color.
+type Palette struct {
+	Background color.Color
+	Foreground color.Color
+	Colors     map[rune]color.Color
+}
+
+// DefaultPalette is a dark palette with colors for the common map characters.
+func DefaultPalette() Palette {
+	return Palette{
+		Background: color.RGBA{R: 0x0f, G: 0x0f, B: 0x23, A: 0xff},
+		Foreground: color.RGBA{R: 0xcc, G: 0xcc, B: 0xcc, A: 0xff},
+		Colors: map[rune]color.Color{
+			'.': color.RGBA{R: 0x33, G: 0x33, B: 0x44, A: 0xff},
+			'#': color.RGBA{R: 0x99, G: 0x99, B: 0x99, A: 0xff},
+			'X': color.RGBA{R: 0x00, G: 0x99, B: 0x00, A: 0xff},
+			'O': color.RGBA{R: 0xff, G: 0xff, B: 0x66, A: 0xff},
+			'^': color.RGBA{R: 0xff, G: 0x33, B: 0x33, A: 0xff},
+			'>': color.RGBA{R: 0xff, G: 0x33, B: 0x33, A: 0xff},
+			'v': color.RGBA{R: 0xff, G: 0x33, B: 0x33, A: 0xff},
+			'<': color.RGBA{R: 0xff, G: 0x33, B: 0x33, A: 0xff},
+		},
+	}
+}
+
+// Color returns the color used to draw the character c.
+func (p Palette) Color(c rune) color.Color {
+	if col, ok := p.Colors[c]; ok {
+		return col
+	}
+	return p.Foreground
+}
+
+// Recorder captures successive frames of a grid while a simulation is running
+// so they can be exported as an animation afterwards.
+type Recorder struct {
+	Every     int           // Only keep every Nth captured frame (1 keeps all)
+	MaxFrames int           // Upper limit on frames kept in memory (0 is unlimited)
+	Delay     time.Duration // How long each frame is shown for
+	CellSize  int           // Size of a single cell in pixels for images
+	Palette   Palette
+
+	frames  []*grid.Grid[rune]
+	pending *grid.Grid[rune]
+	count   int
+}
+
+// NewRecorder creates a Recorder with sensible defaults for small puzzle maps.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		Every:     1,
+		MaxFrames: 1000,
+		Delay:     50 * time.Millisecond,
+		CellSize:  4,
+		Palette:   DefaultPalette(),
+	}
+}
+
+// Capture records the current state of frame.
+//
+// Frames are throttled based on Every, but the most recent frame is always
+// kept so the final state of the simulation will be part of the export.
+// Once MaxFrames is reached every other frame is dropped and the capture rate
+// is halved, which keeps memory bounded for very long simulations.
+func (r *Recorder) Capture(frame *grid.Grid[rune]) {
+	r.count++
+	if r.Every > 1 && (r.count-1)%r.Every != 0 {
+		// Skipped frames are only referenced, not copied. If this ends up
+		// being the last frame we clone it during export.
+		r.pending = frame
+		return
+	}
+	r.pending = nil
+	r.frames = append(r.frames, frame.Clone())
+
+	if r.MaxFrames > 0 && len(r.frames) >= r.MaxFrames {
+		// If the frame we just captured gets dropped, hold on to it as the
+		// pending frame until a newer one comes along
+		last := r.frames[len(r.frames)-1]
+		kept := r.frames[:0]
+		for i := 0; i < len(r.frames); i += 2 {
+			kept = append(kept, r.frames[i])
+		}
+		if kept[len(kept)-1] != last {
+			r.pending = last
+		}
+		clear(r.frames[len(kept):])
+		r.frames = kept
+		r.Every = max(r.Every, 1) * 2
+	}
+}
+
+// Frames returns every frame that has been kept so far.
+func (r *Recorder) Frames() []*grid.Grid[rune] {
+	if r.pending != nil {
+		return append(slices.Clip(r.frames), r.pending.Clone())
+	}
+	return r.frames
+}
+
+// Save exports the recording to path, picking the format from the extension.
+// Supported formats are animated GIFs (.gif) and asciinema casts (.cast).
+func (r *Recorder) Save(path string) error {
+	var write func(*os.File) error
+	switch filepath.Ext(path) {
+	case ".gif":
+		write = func(f *os.File) error { return r.WriteGIF(f) }
+	case ".cast":
+		write = func(f *os.File) error { return r.WriteCast(f) }
+	default:
+		return fmt.Errorf("unsupported visualization format %q", filepath.Ext(path))
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,61 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/grid"
+)
+
+// Records n frames where frame i has the single cell value i, and returns the
+// values of the frames that were kept
+func record(r *Recorder, n int) []rune {
+	frame := grid.New[rune](1, 1)
+	for i := range n {
+		frame.Set(grid.Point{}, rune(i))
+		r.Capture(frame)
+	}
+
+	values := []rune{}
+	for _, f := range r.Frames() {
+		values = append(values, f.Get(grid.Point{}))
+	}
+	return values
+}
+
+func TestCaptureKeepsLastFrame(t *testing.T) {
+	for _, every := range []int{1, 2, 3} {
+		for _, maxFrames := range []int{0, 2, 3, 4, 5, 10} {
+			for n := 1; n <= 50; n++ {
+				r := NewRecorder()
+				r.Every, r.MaxFrames = every, maxFrames
+
+				values := record(r, n)
+				if last := values[len(values)-1]; last != rune(n-1) {
+					t.Errorf("every=%d max=%d frames=%d: last frame is %d, want %d", every, maxFrames, n, last, n-1)
+				}
+				if values[0] != 0 {
+					t.Errorf("every=%d max=%d frames=%d: first frame is %d, want 0", every, maxFrames, n, values[0])
+				}
+				for i := 1; i < len(values); i++ {
+					if values[i] <= values[i-1] {
+						t.Errorf("every=%d max=%d frames=%d: frames out of order %v", every, maxFrames, n, values)
+						break
+					}
+				}
+				if maxFrames > 0 && len(values) > maxFrames {
+					t.Errorf("every=%d max=%d frames=%d: kept %d frames", every, maxFrames, n, len(values))
+				}
+			}
+		}
+	}
+}
+
+func TestCaptureMaxFrames(t *testing.T) {
+	r := NewRecorder()
+	r.MaxFrames = 4
+
+	// a..d, where capturing d fills the recorder and it is halved
+	if got := string(record(r, 4)); got != "\x00\x02\x03" {
+		t.Errorf("frames = %q, want the first, third and last", got)
+	}
+}
@@ -0,0 +1,163 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/grid"
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/intmath"
+)
+
+// Overlay is a set of points drawn on top of a grid snapshot.
+// When Line is set the points are connected in order through the center of
+// each cell (such as a word or a path), otherwise each cell is highlighted.
+type Overlay struct {
+	Points []grid.Point
+	Color  color.Color
+	Line   bool
+}
+
+// Snapshot renders a single still image of a grid, with optional overlays.
+type Snapshot struct {
+	CellSize int // Size of a single cell in pixels
+	Palette  Palette
+	Overlays []Overlay
+}
+
+// NewSnapshot creates a Snapshot using the default palette.
+func NewSnapshot() *Snapshot {
+	return &Snapshot{CellSize: 8, Palette: DefaultPalette()}
+}
+
+// AddPath connects points with a line of the given color.
+func (s *Snapshot) AddPath(points []grid.Point, c color.Color) {
+	s.Overlays = append(s.Overlays, Overlay{Points: points, Color: c, Line: true})
+}
+
+// AddCells highlights each of the points with the given color.
+func (s *Snapshot) AddCells(points []grid.Point, c color.Color) {
+	s.Overlays = append(s.Overlays, Overlay{Points: points, Color: c})
+}
+
+// Save writes the snapshot of g to path, picking the format from the
+// extension. Supported formats are PNG (.png) and SVG (.svg).
+func (s *Snapshot) Save(path string, g *grid.Grid[rune]) error {
+	var write func(io.Writer, *grid.Grid[rune]) error
+	switch filepath.Ext(path) {
+	case ".png":
+		write = s.WritePNG
+	case ".svg":
+		write = s.WriteSVG
+	default:
+		return fmt.Errorf("unsupported snapshot format %q", filepath.Ext(path))
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file, g); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// WritePNG encodes the snapshot of g as a PNG image.
+func (s *Snapshot) WritePNG(w io.Writer, g *grid.Grid[rune]) error {
+	size := max(s.CellSize, 1)
+	img := image.NewRGBA(image.Rect(0, 0, g.Width()*size, g.Height()*size))
+	fill := func(x0, y0, x1, y1 int, c color.Color) {
+		for y := max(y0, 0); y < min(y1, img.Rect.Max.Y); y++ {
+			for x := max(x0, 0); x < min(x1, img.Rect.Max.X); x++ {
+				img.Set(x, y, c)
+			}
+		}
+	}
+
+	fill(0, 0, img.Rect.Max.X, img.Rect.Max.Y, s.Palette.Background)
+	for p, c := range g.All() {
+		fill(p.X*size, p.Y*size, (p.X+1)*size, (p.Y+1)*size, s.Palette.Color(c))
+	}
+
+	for _, o := range s.Overlays {
+		if !o.Line {
+			for _, p := range o.Points {
+				fill(p.X*size, p.Y*size, (p.X+1)*size, (p.Y+1)*size, o.Color)
+			}
+			continue
+		}
+
+		// Lines are drawn by stamping a small square at every pixel along
+		// each segment, which is plenty for the straight and diagonal lines
+		// that show up in grid puzzles.
+		width := max(size/4, 1)
+		for i := 1; i < len(o.Points); i++ {
+			x0, y0 := o.Points[i-1].X*size+size/2, o.Points[i-1].Y*size+size/2
+			x1, y1 := o.Points[i].X*size+size/2, o.Points[i].Y*size+size/2
+			steps := max(intmath.Abs(x1-x0), intmath.Abs(y1-y0), 1)
+			for t := 0; t <= steps; t++ {
+				x := x0 + (x1-x0)*t/steps
+				y := y0 + (y1-y0)*t/steps
+				fill(x-width/2, y-width/2, x-width/2+width, y-width/2+width, o.Color)
+			}
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// WriteSVG encodes the snapshot of g as an SVG image.
+// Cells that match the background color are not drawn to keep files small.
+func (s *Snapshot) WriteSVG(w io.Writer, g *grid.Grid[rune]) error {
+	size := max(s.CellSize, 1)
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		g.Width()*size, g.Height()*size, g.Width()*size, g.Height()*size)
+	fmt.Fprintf(out, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hexColor(s.Palette.Background))
+
+	bg := hexColor(s.Palette.Background)
+	for p, c := range g.All() {
+		fill := hexColor(s.Palette.Color(c))
+		if fill == bg {
+			continue
+		}
+		fmt.Fprintf(out, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+			p.X*size, p.Y*size, size, size, fill)
+	}
+
+	for _, o := range s.Overlays {
+		if !o.Line {
+			for _, p := range o.Points {
+				fmt.Fprintf(out, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+					p.X*size, p.Y*size, size, size, hexColor(o.Color))
+			}
+			continue
+		}
+
+		fmt.Fprintf(out, `<polyline fill="none" stroke="%s" stroke-width="%d" stroke-linecap="round" stroke-linejoin="round" points="`,
+			hexColor(o.Color), max(size/4, 1))
+		for i, p := range o.Points {
+			if i > 0 {
+				out.WriteByte(' ')
+			}
+			fmt.Fprintf(out, "%d,%d", p.X*size+size/2, p.Y*size+size/2)
+		}
+		out.WriteString(`"/>` + "\n")
+	}
+
+	out.WriteString("</svg>\n")
+	return out.Flush()
+}
+
+// Converts a color into an #rrggbb hex string
+func hexColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
@@ -0,0 +1,36 @@
+package search
+
+import (
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/grid"
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/intmath"
+)
+
+// GridNeighbors lets the searches walk across a grid, moving one cell at a
+// time in the 4 cardinal directions onto cells that are passable.
+// Every move costs 1.
+func GridNeighbors[T any](g *grid.Grid[T], passable func(T) bool) Neighbors[grid.Point] {
+	return func(p grid.Point) []Edge[grid.Point] {
+		edges := make([]Edge[grid.Point], 0, 4)
+		for n := range g.Neighbors(p, grid.Orthogonal) {
+			if passable(g.Get(n)) {
+				edges = append(edges, Edge[grid.Point]{To: n, Cost: 1})
+			}
+		}
+		return edges
+	}
+}
+
+// Manhattan is an A* heuristic for grids where you can only move in the
+// cardinal directions.
+func Manhattan(goal grid.Point) func(grid.Point) int {
+	return func(p grid.Point) int {
+		return intmath.Abs(p.X-goal.X) + intmath.Abs(p.Y-goal.Y)
+	}
+}
+
+// Chebyshev is an A* heuristic for grids where you can also move diagonally.
+func Chebyshev(goal grid.Point) func(grid.Point) int {
+	return func(p grid.Point) int {
+		return max(intmath.Abs(p.X-goal.X), intmath.Abs(p.Y-goal.Y))
+	}
+}
@@ -0,0 +1,256 @@
+// Package search has generic graph searches (BFS, DFS, Dijkstra and A*) over
+// any state type, where the graph is described by a neighbors callback instead
+// of being built up front. This works just as well for grid mazes as it does
+// for more abstract states, like a position and heading.
+package search
+
+import (
+	"container/heap"
+	"slices"
+)
+
+// Edge is a move to another state, and how much it costs to make it.
+type Edge[S comparable] struct {
+	To   S
+	Cost int
+}
+
+// Neighbors returns every state that can be reached from a state in one move.
+type Neighbors[S comparable] func(S) []Edge[S]
+
+// Result is everything a search found out about the graph.
+type Result[S comparable] struct {
+	Start S
+	Goal  S    // The goal state that was reached, if any
+	Found bool // Whether a goal state was reached
+	Dist  map[S]int
+	Prev  map[S][]S // Every predecessor that leads to a state in the shortest distance
+	Order []S       // States in the order they were visited
+}
+
+func newResult[S comparable](start S) *Result[S] {
+	return &Result[S]{
+		Start: start,
+		Dist:  map[S]int{start: 0},
+		Prev:  map[S][]S{},
+	}
+}
+
+// Records an edge from -> to with a total distance, returning whether it was an
+// improvement (or an equally short alternative path)
+func (r *Result[S]) relax(from S, to S, dist int) bool {
+	if d, ok := r.Dist[to]; !ok || dist < d {
+		r.Dist[to] = dist
+		r.Prev[to] = append(r.Prev[to][:0], from)
+		return true
+	} else if dist == d && !slices.Contains(r.Prev[to], from) {
+		r.Prev[to] = append(r.Prev[to], from)
+	}
+	return false
+}
+
+// Path returns a shortest path from the start to a state (including both),
+// or nil if it was never reached.
+func (r *Result[S]) Path(to S) []S {
+	if _, ok := r.Dist[to]; !ok {
+		return nil
+	}
+
+	path := []S{to}
+	for to != r.Start {
+		to = r.Prev[to][0]
+		path = append(path, to)
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// AllPaths returns every shortest path from the start to a state. There can
+// be a lot of them, so OnPath is usually a better choice when only the states
+// themselves are needed.
+func (r *Result[S]) AllPaths(to S) [][]S {
+	if _, ok := r.Dist[to]; !ok {
+		return nil
+	}
+	if to == r.Start {
+		return [][]S{{to}}
+	}
+
+	paths := [][]S{}
+	for _, prev := range r.Prev[to] {
+		for _, path := range r.AllPaths(prev) {
+			paths = append(paths, append(path, to))
+		}
+	}
+	return paths
+}
+
+// OnPath returns every state that is on at least one shortest path from the
+// start to a state.
+func (r *Result[S]) OnPath(to S) map[S]bool {
+	on := map[S]bool{}
+	if _, ok := r.Dist[to]; !ok {
+		return on
+	}
+
+	stack := []S{to}
+	on[to] = true
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, prev := range r.Prev[s] {
+			if !on[prev] {
+				on[prev] = true
+				stack = append(stack, prev)
+			}
+		}
+	}
+	return on
+}
+
+// BFS does a breadth-first search from start, ignoring edge costs so every
+// move counts as 1. It stops once a state matching goal is reached, or
+// explores everything reachable if goal is nil.
+func BFS[S comparable](start S, neighbors Neighbors[S], goal func(S) bool) *Result[S] {
+	r := newResult(start)
+	queue := []S{start}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		r.Order = append(r.Order, s)
+		if goal != nil && goal(s) {
+			r.Goal, r.Found = s, true
+			return r
+		}
+
+		for _, e := range neighbors(s) {
+			if r.relax(s, e.To, r.Dist[s]+1) {
+				queue = append(queue, e.To)
+			}
+		}
+	}
+	return r
+}
+
+// DFS does a depth-first search from start, stopping once a state matching
+// goal is reached (or exploring everything if goal is nil).
+// Distances are the depth in the search tree, which are not the shortest.
+func DFS[S comparable](start S, neighbors Neighbors[S], goal func(S) bool) *Result[S] {
+	r := newResult(start)
+	visited := map[S]bool{}
+	stack := []S{start}
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[s] {
+			continue
+		}
+		visited[s] = true
+		r.Order = append(r.Order, s)
+		if goal != nil && goal(s) {
+			r.Goal, r.Found = s, true
+			return r
+		}
+
+		// Push neighbors in reverse so they are visited in the order given
+		edges := neighbors(s)
+		for i := len(edges) - 1; i >= 0; i-- {
+			to := edges[i].To
+			if !visited[to] {
+				r.Dist[to] = r.Dist[s] + 1
+				r.Prev[to] = []S{s}
+				stack = append(stack, to)
+			}
+		}
+	}
+	return r
+}
+
+// Dijkstra finds the shortest distance from start to every state, stopping
+// once a state matching goal is reached (or exploring everything if goal is
+// nil). Edge costs must not be negative.
+func Dijkstra[S comparable](start S, neighbors Neighbors[S], goal func(S) bool) *Result[S] {
+	return AStar(start, neighbors, goal, nil)
+}
+
+// AStar is Dijkstra, but explores states that the heuristic thinks are closer
+// to the goal first. The heuristic must never overestimate the remaining cost,
+// otherwise the path found may not be the shortest. If it isn't also consistent
+// (the estimate never drops by more than the cost of a move) a state can be
+// found a shorter way after it was visited, so it gets visited again and may
+// show up more than once in Order. A nil heuristic is the same as Dijkstra.
+//
+// Once the goal is reached, the states that are just as promising are still
+// explored so that Prev has every shortest path to it.
+func AStar[S comparable](start S, neighbors Neighbors[S], goal func(S) bool, heuristic func(S) int) *Result[S] {
+	estimate := func(s S) int {
+		if heuristic == nil {
+			return 0
+		}
+		return heuristic(s)
+	}
+
+	r := newResult(start)
+	done := map[S]bool{}
+	pq := &queue[S]{}
+	heap.Push(pq, item[S]{state: start, priority: estimate(start)})
+	bound := 0 // Priority the goal was reached with
+	for pq.Len() > 0 {
+		it := heap.Pop(pq).(item[S])
+		if r.Found && it.priority > bound {
+			break // Nothing left can be on a shortest path to the goal
+		}
+		if done[it.state] {
+			continue // Stale entry, we already found a shorter way here
+		}
+		done[it.state] = true
+		r.Order = append(r.Order, it.state)
+		if goal != nil && goal(it.state) {
+			if !r.Found {
+				r.Goal, r.Found, bound = it.state, true, it.priority
+			}
+			continue
+		}
+
+		dist := r.Dist[it.state]
+		for _, e := range neighbors(it.state) {
+			if done[e.To] {
+				if d := r.Dist[e.To]; dist+e.Cost > d {
+					continue
+				} else if dist+e.Cost == d {
+					// Another way there that's just as short, unless it's back
+					// along a free move that got us here
+					if e.Cost > 0 || !r.OnPath(it.state)[e.To] {
+						r.relax(it.state, e.To, d)
+					}
+					continue
+				}
+				delete(done, e.To) // Found a shorter way, so it has to be visited again
+			}
+			if r.relax(it.state, e.To, dist+e.Cost) {
+				heap.Push(pq, item[S]{state: e.To, priority: dist + e.Cost + estimate(e.To)})
+			}
+		}
+	}
+	return r
+}
+
+// Priority queue entry for Dijkstra and A*
+type item[S comparable] struct {
+	state    S
+	priority int
+}
+
+// Min-heap of states implementing heap.Interface
+type queue[S comparable] []item[S]
+
+func (q queue[S]) Len() int           { return len(q) }
+func (q queue[S]) Less(i, j int) bool { return q[i].priority < q[j].priority }
+func (q queue[S]) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *queue[S]) Push(x any)        { *q = append(*q, x.(item[S])) }
+func (q *queue[S]) Pop() any {
+	old := *q
+	it := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return it
+}
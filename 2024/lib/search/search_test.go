@@ -0,0 +1,132 @@
+package search
+
+import (
+	"maps"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/grid"
+)
+
+func TestAStarInconsistentHeuristic(t *testing.T) {
+	// The heuristic never overestimates, but it drops by 4 going from A to B,
+	// which only costs 1. So B is first reached the long way, before A.
+	edges := map[string][]Edge[string]{
+		"S": {{To: "A", Cost: 1}, {To: "B", Cost: 3}},
+		"A": {{To: "B", Cost: 1}},
+		"B": {{To: "G", Cost: 3}},
+	}
+	estimates := map[string]int{"S": 0, "A": 4, "B": 0, "G": 0}
+
+	r := AStar("S",
+		func(s string) []Edge[string] { return edges[s] },
+		func(s string) bool { return s == "G" },
+		func(s string) int { return estimates[s] })
+	if !r.Found || r.Dist["G"] != 5 {
+		t.Fatalf("AStar found G at %d, want 5", r.Dist["G"])
+	}
+	if path := r.Path("G"); len(path) != 4 || path[1] != "A" {
+		t.Errorf("AStar path = %v, want [S A B G]", path)
+	}
+}
+
+func TestAStarMatchesDijkstra(t *testing.T) {
+	rng := rand.New(rand.NewSource(39))
+	for range 200 {
+		// Random directed graph, some states may not reach the goal at all
+		n := 2 + rng.Intn(15)
+		edges := make([][]Edge[int], n)
+		for range rng.Intn(n * 4) {
+			from, to := rng.Intn(n), rng.Intn(n)
+			edges[from] = append(edges[from], Edge[int]{To: to, Cost: rng.Intn(10)})
+		}
+		neighbors := func(s int) []Edge[int] { return edges[s] }
+		goal := func(s int) bool { return s == n-1 }
+
+		// A random fraction of the real remaining cost is always admissible,
+		// but almost never consistent
+		estimates := make([]int, n)
+		for s := range n {
+			if r := Dijkstra(s, neighbors, goal); r.Found {
+				estimates[s] = rng.Intn(r.Dist[r.Goal] + 1)
+			}
+		}
+
+		want := Dijkstra(0, neighbors, goal)
+		got := AStar(0, neighbors, goal, func(s int) int { return estimates[s] })
+		if got.Found != want.Found || got.Dist[got.Goal] != want.Dist[want.Goal] {
+			t.Errorf("AStar = %d, %t, want %d, %t (edges %v, estimates %v)",
+				got.Dist[got.Goal], got.Found, want.Dist[want.Goal], want.Found, edges, estimates)
+		}
+	}
+}
+
+// Builds a random maze with roughly the given fraction of walls, and always
+// leaves the corners open
+func randomGrid(rng *rand.Rand, width int, height int, density float64) *grid.Grid[byte] {
+	g := grid.New[byte](width, height)
+	for p := range g.All() {
+		if rng.Float64() < density {
+			g.Set(p, '#')
+		} else {
+			g.Set(p, '.')
+		}
+	}
+	g.Set(grid.Point{X: 0, Y: 0}, '.')
+	g.Set(grid.Point{X: width - 1, Y: height - 1}, '.')
+	return g
+}
+
+func TestAStarAllShortestPaths(t *testing.T) {
+	rng := rand.New(rand.NewSource(39))
+	grids := []*grid.Grid[byte]{randomGrid(rng, 5, 5, 0)} // Open grid, so 70 paths
+	for range 200 {
+		grids = append(grids, randomGrid(rng, 2+rng.Intn(8), 2+rng.Intn(8), 0.25))
+	}
+
+	for _, g := range grids {
+		start, end := grid.Point{X: 0, Y: 0}, grid.Point{X: g.Width() - 1, Y: g.Height() - 1}
+		neighbors := GridNeighbors(g, func(c byte) bool { return c == '.' })
+		isEnd := func(p grid.Point) bool { return p == end }
+
+		want := BFS(start, neighbors, isEnd)
+		for name, r := range map[string]*Result[grid.Point]{
+			"Dijkstra": Dijkstra(start, neighbors, isEnd),
+			"AStar":    AStar(start, neighbors, isEnd, Manhattan(end)),
+		} {
+			if r.Found != want.Found || r.Dist[end] != want.Dist[end] {
+				t.Fatalf("%s = %d, %t, want %d, %t", name, r.Dist[end], r.Found, want.Dist[end], want.Found)
+			}
+			if got, want := r.OnPath(end), want.OnPath(end); !maps.Equal(got, want) {
+				t.Errorf("%s OnPath has %d states, want %d", name, len(got), len(want))
+			}
+			if got, want := len(r.AllPaths(end)), len(want.AllPaths(end)); got != want {
+				t.Errorf("%s found %d shortest paths, want %d", name, got, want)
+			}
+		}
+	}
+}
+
+func TestZeroCostTies(t *testing.T) {
+	edges := map[string][]Edge[string]{
+		"S": {{To: "B", Cost: 1}, {To: "A", Cost: 1}},
+		"A": {{To: "B", Cost: 0}},
+		"B": {{To: "A", Cost: 0}, {To: "G", Cost: 1}},
+	}
+	neighbors := func(s string) []Edge[string] { return edges[s] }
+
+	r := Dijkstra("S", neighbors, func(s string) bool { return s == "B" })
+	if !slices.Equal(r.Prev["B"], []string{"S", "A"}) {
+		t.Errorf("Prev[B] = %v, want [S A]", r.Prev["B"])
+	}
+
+	// The free moves go both ways, which must not turn into a loop in Prev
+	// (AllPaths would never return)
+	r = Dijkstra("S", neighbors, nil)
+	for _, path := range r.AllPaths("G") {
+		if len(path) > 4 {
+			t.Errorf("AllPaths(G) has a path through a loop: %v", path)
+		}
+	}
+}
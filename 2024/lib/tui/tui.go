@@ -0,0 +1,254 @@
+// Package tui is a small interactive terminal viewer for stepping through grid
+// simulations, so wrong answers can be debugged without sprinkling print
+// statements around.
+//
+// Raw mode is set up with stty so only the standard library is needed. If that
+// isn't available it falls back to reading whole lines, where every character
+// typed is treated as a key press.
+package tui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/IAreKyleW00t/advent-of-code/2024/lib/grid"
+)
+
+// Stepper is a simulation that has already been run, and can be viewed at any
+// step between 0 and Steps().
+type Stepper interface {
+	Steps() int                      // Index of the last step
+	Frame(step int) *grid.Grid[rune] // Drawing of the simulation at a step
+	Marker(step int) bool            // Whether a step is worth stopping at, such as a turn
+	Toggle(p grid.Point) bool        // Toggle an obstruction and re-run, false if not allowed
+	Status(step int) string          // Short description of the simulation at a step
+}
+
+// Keys that are not a single printable character
+const (
+	keyUp = iota + 0x100
+	keyDown
+	keyRight
+	keyLeft
+)
+
+const help = "←↑↓→/hjkl cursor  n/b step  t/T turn  g/G start/end  o toggle  q quit"
+
+// Viewer state between key presses
+type viewer struct {
+	sim    Stepper
+	step   int
+	cursor grid.Point
+	rows   int // Terminal size, used to scroll large maps
+	cols   int
+	msg    string
+}
+
+// Run shows the simulation in the terminal until the user quits.
+// Keys are read from the terminal itself rather than stdin, since stdin is
+// usually the puzzle input.
+func Run(sim Stepper) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("interactive mode needs a terminal: %w", err)
+	}
+	defer tty.Close()
+
+	v := &viewer{sim: sim, rows: 24, cols: 80}
+	if rows, cols, err := termSize(tty); err == nil {
+		v.rows, v.cols = rows, cols
+	}
+
+	// Fall back to line mode if we can't switch the terminal to raw mode
+	restore, err := makeRaw(tty)
+	if err != nil {
+		v.msg = "raw mode unavailable, press Enter after each key"
+	} else {
+		defer restore()
+	}
+
+	out := bufio.NewWriter(tty)
+	out.WriteString("\x1b[?1049h\x1b[?25l") // Alternate screen, hide cursor
+	defer func() {
+		out.WriteString("\x1b[?25h\x1b[?1049l")
+		out.Flush()
+	}()
+
+	in := bufio.NewReader(tty)
+	for {
+		v.draw(out)
+		if err := out.Flush(); err != nil {
+			return err
+		}
+
+		key, err := readKey(in)
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if !v.handle(key) {
+			return nil
+		}
+	}
+}
+
+// Updates the viewer for a single key press, returning false to quit
+func (v *viewer) handle(key int) bool {
+	v.msg = ""
+	frame := v.sim.Frame(v.step)
+	move := func(d grid.Direction) {
+		if next := v.cursor.Add(d.Delta()); frame.In(next) {
+			v.cursor = next
+		}
+	}
+
+	switch key {
+	case 'q', 3: // Ctrl-C doesn't send a signal in raw mode
+		return false
+	case keyUp, 'k':
+		move(grid.North)
+	case keyDown, 'j':
+		move(grid.South)
+	case keyRight, 'l':
+		move(grid.East)
+	case keyLeft, 'h':
+		move(grid.West)
+	case 'n', ' ':
+		v.step = min(v.step+1, v.sim.Steps())
+	case 'b':
+		v.step = max(v.step-1, 0)
+	case 't':
+		for v.step < v.sim.Steps() {
+			v.step++
+			if v.sim.Marker(v.step) {
+				break
+			}
+		}
+	case 'T':
+		for v.step > 0 {
+			v.step--
+			if v.sim.Marker(v.step) {
+				break
+			}
+		}
+	case 'g':
+		v.step = 0
+	case 'G':
+		v.step = v.sim.Steps()
+	case 'o', '\r':
+		if !v.sim.Toggle(v.cursor) {
+			v.msg = fmt.Sprintf("can't toggle [x=%d, y=%d]", v.cursor.X, v.cursor.Y)
+		}
+		// The new simulation may be shorter than the old one
+		v.step = min(v.step, v.sim.Steps())
+	}
+	return true
+}
+
+// Draws the current step, scrolling the map so the cursor is always visible
+func (v *viewer) draw(out *bufio.Writer) {
+	frame := v.sim.Frame(v.step)
+	height := min(frame.Height(), max(v.rows-3, 1))
+	width := min(frame.Width(), max(v.cols, 1))
+	top := min(max(v.cursor.Y-height/2, 0), frame.Height()-height)
+	left := min(max(v.cursor.X-width/2, 0), frame.Width()-width)
+
+	out.WriteString("\x1b[H\x1b[2J")
+	for y := top; y < top+height; y++ {
+		for x := left; x < left+width; x++ {
+			p := grid.Point{X: x, Y: y}
+			if p == v.cursor {
+				out.WriteString("\x1b[7m") // Reverse video
+				out.WriteRune(frame.Get(p))
+				out.WriteString("\x1b[27m")
+			} else {
+				out.WriteRune(frame.Get(p))
+			}
+		}
+		out.WriteString("\r\n")
+	}
+
+	fmt.Fprintf(out, "step %d/%d  cursor [x=%d, y=%d]  %s\r\n",
+		v.step, v.sim.Steps(), v.cursor.X, v.cursor.Y, v.sim.Status(v.step))
+	if v.msg != "" {
+		out.WriteString(v.msg)
+	} else {
+		out.WriteString(help)
+	}
+}
+
+// Reads a single key press, turning arrow key escape sequences into a key code
+func readKey(in *bufio.Reader) (int, error) {
+	for {
+		c, err := in.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		switch c {
+		case '\n': // Only sent in line mode
+			continue
+		case 0x1b:
+			// Arrow keys are sent as ESC [ A-D all at once, so if nothing else
+			// has arrived yet then Esc was pressed on its own. Peeking would
+			// block until the next 2 key presses.
+			if in.Buffered() < 2 {
+				break
+			}
+			if b, _ := in.Peek(2); b[0] == '[' && b[1] >= 'A' && b[1] <= 'D' {
+				in.Discard(2)
+				return [...]int{keyUp, keyDown, keyRight, keyLeft}[b[1]-'A'], nil
+			}
+		}
+		return int(c), nil
+	}
+}
+
+// Utility function to run stty against the terminal
+func stty(tty *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// Switches the terminal into raw mode, returning a function that restores the
+// original settings
+func makeRaw(tty *os.File) (func(), error) {
+	state, err := stty(tty, "-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty(tty, "raw", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() { stty(tty, state) }, nil
+}
+
+// Looks up the number of rows and columns in the terminal
+func termSize(tty *os.File) (int, int, error) {
+	size, err := stty(tty, "size")
+	if err != nil {
+		return 0, 0, err
+	}
+	fields := strings.Fields(size)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected terminal size %q", size)
+	}
+	rows, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	cols, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return rows, cols, nil
+}
@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"bufio"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestReadKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []int
+	}{
+		{"arrows", "\x1b[A\x1b[B\x1b[C\x1b[D", []int{keyUp, keyDown, keyRight, keyLeft}},
+		{"letters", "o\nq", []int{'o', 'q'}},
+		{"escape then letter", "\x1bx", []int{0x1b, 'x'}},
+		{"not an arrow", "\x1b[Z", []int{0x1b, '[', 'Z'}},
+	}
+	for _, tt := range tests {
+		// Escape sequences arrive whole, like a terminal sends them
+		r, w := io.Pipe()
+		go func() {
+			w.Write([]byte(tt.input))
+			w.Close()
+		}()
+
+		in := bufio.NewReader(r)
+		for i, want := range tt.want {
+			if got, err := readKey(in); err != nil || got != want {
+				t.Errorf("%s: key %d = %d, %v, want %d", tt.name, i, got, err, want)
+			}
+		}
+	}
+}
+
+func TestReadKeyBareEscape(t *testing.T) {
+	// Nothing is sent after Esc, so readKey must not wait for more
+	r, w := io.Pipe()
+	defer w.Close()
+	go w.Write([]byte{0x1b})
+
+	done := make(chan int)
+	go func() {
+		key, _ := readKey(bufio.NewReader(r))
+		done <- key
+	}()
+	select {
+	case key := <-done:
+		if key != 0x1b {
+			t.Errorf("readKey = %d, want Esc", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("readKey is still waiting after Esc was pressed")
+	}
+}